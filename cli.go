@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+
+	"cardboard-hunter/internal/checker"
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/storage"
+)
+
+// runCheck checks games without starting the server and prints the results.
+// Exit code is 1 when every store check failed, 2 on usage errors.
+func runCheck(args []string) int {
+	fset := flag.NewFlagSet("check", flag.ContinueOnError)
+	wishlist := fset.String("wishlist", "", "check every game in this games.json file")
+	format := fset.String("format", "table", "output format: table, json or csv")
+	record := fset.Bool("record", false, "save results and price history to --data-dir like the web UI")
+	dataDir := fset.String("data-dir", ".", "directory for history and results (with --record)")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), `Usage: cardboard-hunter check [flags] "Game 1" "Game 2" ...`)
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+
+	var games []models.Game
+	if *wishlist != "" {
+		loaded, err := storage.New(*wishlist).LoadGames()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load wishlist: %v\n", err)
+			return 1
+		}
+		games = loaded
+	}
+	for _, name := range fset.Args() {
+		games = append(games, models.Game{Name: name, Priority: len(games) + 1})
+	}
+	if len(games) == 0 {
+		fset.Usage()
+		return 2
+	}
+
+	var write func(io.Writer, models.CheckResponse) error
+	switch *format {
+	case "table":
+		write = writeTable
+	case "json":
+		write = writeJSON
+	case "csv":
+		write = writeCSV
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q (use table, json or csv)\n", *format)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	c := checker.New()
+	results := c.CheckGames(ctx, games)
+
+	var response models.CheckResponse
+	if *record {
+		openStorage(*dataDir)
+		response = completeCheck(ctx, c, results)
+	} else {
+		response = models.CheckResponse{
+			Results:  results,
+			Summary:  c.CalculateSummary(results),
+			AtTarget: c.CalculateTargetSummary(results),
+			OnSale:   c.CalculateSaleSummary(results),
+		}
+	}
+
+	if err := write(os.Stdout, response); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write results: %v\n", err)
+		return 1
+	}
+
+	if ctx.Err() != nil || allFailed(results) {
+		return 1
+	}
+	return 0
+}
+
+// allFailed reports whether no store answered without an error
+func allFailed(results []models.GameResult) bool {
+	for _, gr := range results {
+		for _, sr := range gr.Results {
+			if sr.Error == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func resultStatus(sr models.StoreResult) string {
+	switch {
+	case sr.ErrorKind != "":
+		return "error (" + string(sr.ErrorKind) + ")"
+	case sr.Error != "":
+		return "error"
+	case !sr.Found:
+		return "not found"
+	case sr.InStock:
+		return "in stock"
+	default:
+		return "out of stock"
+	}
+}
+
+func writeTable(w io.Writer, resp models.CheckResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GAME\tSTORE\tSTATUS\tPRICE\tTITLE")
+	for _, gr := range resp.Results {
+		for _, sr := range gr.Results {
+			detail := sr.Title
+			if sr.Error != "" {
+				detail = sr.Error
+			}
+			price := sr.Price
+			if sr.OnSale {
+				price += fmt.Sprintf(" (-%d%%)", sr.DiscountPercent)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", gr.Name, sr.Store, resultStatus(sr), price, detail)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(resp.Results) == 0 {
+		return nil
+	}
+
+	// Walk the first game's results rather than the map to keep store order stable
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "In stock per store:")
+	for _, sr := range resp.Results[0].Results {
+		fmt.Fprintf(w, "  %s: %d", sr.Store, resp.Summary[sr.Store])
+		if n := resp.OnSale[sr.Store]; n > 0 {
+			fmt.Fprintf(w, " (%d on sale)", n)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, resp models.CheckResponse) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(resp)
+}
+
+func writeCSV(w io.Writer, resp models.CheckResponse) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"game", "store", "status", "price", "priceNum", "title", "url", "error", "regularPrice", "errorKind"})
+	for _, gr := range resp.Results {
+		for _, sr := range gr.Results {
+			cw.Write([]string{
+				gr.Name,
+				sr.Store,
+				resultStatus(sr),
+				sr.Price,
+				strconv.FormatFloat(sr.PriceNum, 'f', -1, 64),
+				sr.Title,
+				sr.URL,
+				sr.Error,
+				strconv.FormatFloat(sr.RegularPrice, 'f', -1, 64),
+				string(sr.ErrorKind),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -1,3 +1,9 @@
 module cardboard-hunter
 
 go 1.21
+
+require (
+	github.com/andybalholm/cascadia v1.3.2
+	golang.org/x/net v0.25.0
+	golang.org/x/text v0.15.0
+)
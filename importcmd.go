@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cardboard-hunter/internal/bgg"
+	"cardboard-hunter/internal/storage"
+)
+
+// runImportBGG merges the wanted games of a saved BGG collection export into games.json
+func runImportBGG(args []string) int {
+	fset := flag.NewFlagSet("import-bgg", flag.ContinueOnError)
+	dataDir := fset.String("data-dir", ".", "directory holding games.json")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: cardboard-hunter import-bgg [flags] <collection.xml>")
+		fmt.Fprintln(fset.Output(), "\nThe file is a saved answer of https://boardgamegeek.com/xmlapi2/collection?username=<name>")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	if fset.NArg() != 1 {
+		fset.Usage()
+		return 2
+	}
+
+	f, err := os.Open(fset.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+
+	collection, err := bgg.ParseCollection(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fset.Arg(0), err)
+		return 1
+	}
+
+	path := filepath.Join(*dataDir, "games.json")
+	st := storage.New(path)
+	games, err := st.LoadGames()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load %s: %v\n", path, err)
+		return 1
+	}
+
+	res, err := bgg.Merge(games, collection)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := st.SaveGames(res.Games); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save %s: %v\n", path, err)
+		return 1
+	}
+
+	fmt.Printf("Added %d games, linked %d existing games to BGG, %d already on the wishlist\n",
+		res.Added, res.Linked, res.Skipped)
+	fmt.Printf("%s now lists %d games\n", path, len(res.Games))
+	return 0
+}
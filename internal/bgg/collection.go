@@ -0,0 +1,192 @@
+// Package bgg imports wishlists from BoardGameGeek collection exports
+package bgg
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/utils"
+)
+
+// BGG wishlist priorities, from "Must have" to "Don't buy this"
+const (
+	PriorityMustHave  = 1
+	PriorityDontBuy   = 5
+	defaultPriority   = 3 // "Like to have", for want-to-buy items not on the wishlist
+	subtypeExpansion  = "boardgameexpansion"
+	collectionElement = "items"
+)
+
+// Collection is a collection export saved from /xmlapi2/collection
+type Collection struct {
+	Items []Item `xml:"item"`
+}
+
+// Item is a single game of a collection
+type Item struct {
+	ObjectID        int    `xml:"objectid,attr"`
+	Subtype         string `xml:"subtype,attr"` // "boardgame" or "boardgameexpansion"
+	Name            string `xml:"name"`
+	Status          Status `xml:"status"`
+	WishlistComment string `xml:"wishlistcomment"`
+}
+
+// Status holds the collection flags of an item
+type Status struct {
+	WantToBuy        bool `xml:"wanttobuy,attr"`
+	Wishlist         bool `xml:"wishlist,attr"`
+	WishlistPriority int  `xml:"wishlistpriority,attr"` // 1-5, set for wishlist items
+}
+
+// bggAnswer is the root element of what BGG answered: <items> for a
+// collection, <errors> for an error, or a <message> asking to try again
+// later while the export is being prepared
+type bggAnswer struct {
+	XMLName xml.Name
+	Text    string   `xml:",chardata"`
+	Errors  []string `xml:"error>message"`
+}
+
+// ParseCollection reads a collection export. BGG error and "try again later"
+// answers saved in place of the export are reported as errors.
+func ParseCollection(r io.Reader) (*Collection, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var answer bggAnswer
+	if err := xml.Unmarshal(data, &answer); err != nil {
+		return nil, fmt.Errorf("not a BGG collection export: %v", err)
+	}
+	switch answer.XMLName.Local {
+	case collectionElement:
+	case "errors":
+		return nil, fmt.Errorf("BGG returned an error: %s", strings.Join(answer.Errors, "; "))
+	case "message":
+		return nil, fmt.Errorf("BGG returned a message instead of a collection: %s", strings.TrimSpace(answer.Text))
+	default:
+		return nil, fmt.Errorf("not a BGG collection export: root element is <%s>, want <%s>", answer.XMLName.Local, collectionElement)
+	}
+
+	var c Collection
+	if err := xml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("not a BGG collection export: %v", err)
+	}
+	return &c, nil
+}
+
+// Wanted returns the items marked "want to buy" or on the wishlist (except
+// "Don't buy this"), most wanted first
+func (c *Collection) Wanted() []Item {
+	var wanted []Item
+	for _, it := range c.Items {
+		if strings.TrimSpace(it.Name) == "" {
+			continue
+		}
+		if it.Status.WantToBuy || (it.Status.Wishlist && it.Status.WishlistPriority != PriorityDontBuy) {
+			wanted = append(wanted, it)
+		}
+	}
+	sort.SliceStable(wanted, func(i, j int) bool {
+		return wanted[i].priority() < wanted[j].priority()
+	})
+	return wanted
+}
+
+// priority is the item's wishlist priority, with want-to-buy items that are
+// not on the wishlist ranked as "Like to have"
+func (it Item) priority() int {
+	if it.Status.Wishlist && it.Status.WishlistPriority >= PriorityMustHave && it.Status.WishlistPriority < PriorityDontBuy {
+		return it.Status.WishlistPriority
+	}
+	return defaultPriority
+}
+
+// Game converts an item into a wishlist game. "Must have" items are starred.
+func (it Item) Game() models.Game {
+	return models.Game{
+		Name:      strings.TrimSpace(it.Name),
+		Starred:   it.priority() == PriorityMustHave,
+		Notes:     strings.TrimSpace(it.WishlistComment),
+		Expansion: it.Subtype == subtypeExpansion,
+		BGGID:     it.ObjectID,
+	}
+}
+
+// MergeResult reports what Merge changed
+type MergeResult struct {
+	Games   []models.Game `json:"games"`
+	Added   int           `json:"added"`   // new games appended to the wishlist
+	Linked  int           `json:"linked"`  // existing games given their BGG ID
+	Skipped int           `json:"skipped"` // wanted items already on the wishlist
+}
+
+// ErrNothingWanted is returned by Merge when the collection has no
+// want-to-buy or wishlist items
+var ErrNothingWanted = errors.New("the collection has no want-to-buy or wishlist items")
+
+// Merge adds the collection's wanted items to games. A game already on the
+// wishlist (same BGG ID, or the same name once normalized) is kept as is,
+// only gaining its BGG ID. A new game takes its BGG wishlist priority
+// (1-4) and is placed after the existing games of the same or higher
+// priority, so a "Must have" lands near the top; priorities are then
+// renumbered to list positions, as the wishlist orders games by position.
+func Merge(games []models.Game, c *Collection) (MergeResult, error) {
+	wanted := c.Wanted()
+	if len(wanted) == 0 {
+		return MergeResult{}, ErrNothingWanted
+	}
+
+	res := MergeResult{Games: append([]models.Game(nil), games...)}
+	byID := make(map[int]int)
+	byName := make(map[string]int)
+	for i, g := range res.Games {
+		if g.BGGID != 0 {
+			byID[g.BGGID] = i
+		}
+		byName[utils.NormalizeTitle(g.Name)] = i
+	}
+
+	for _, it := range wanted {
+		game := it.Game()
+		key := utils.NormalizeTitle(game.Name)
+
+		i, ok := byID[game.BGGID]
+		if !ok {
+			i, ok = byName[key]
+		}
+		if ok {
+			if res.Games[i].BGGID == 0 && game.BGGID != 0 {
+				res.Games[i].BGGID = game.BGGID
+				byID[game.BGGID] = i
+				res.Linked++
+			} else {
+				res.Skipped++
+			}
+			continue
+		}
+
+		game.Priority = it.priority()
+		res.Games = append(res.Games, game)
+		if game.BGGID != 0 {
+			byID[game.BGGID] = len(res.Games) - 1
+		}
+		byName[key] = len(res.Games) - 1
+		res.Added++
+	}
+
+	// Stable, so existing games keep their order and win ties
+	sort.SliceStable(res.Games, func(i, j int) bool {
+		return res.Games[i].Priority < res.Games[j].Priority
+	})
+	for i := range res.Games {
+		res.Games[i].Priority = i + 1
+	}
+	return res, nil
+}
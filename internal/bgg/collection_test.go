@@ -0,0 +1,99 @@
+package bgg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cardboard-hunter/internal/models"
+)
+
+const export = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<items totalitems="6" termsofuse="https://boardgamegeek.com/xmlapi/termsofuse" pubdate="Sat, 17 Oct 2026 12:00:00 +0000">
+	<item objecttype="thing" objectid="266192" subtype="boardgame" collid="1">
+		<name sortindex="1">Wingspan</name>
+		<status own="0" prevowned="0" fortrade="0" want="0" wanttoplay="0" wanttobuy="0" wishlist="1" wishlistpriority="3" preordered="0" lastmodified="2026-01-02 10:00:00" />
+	</item>
+	<item objecttype="thing" objectid="9209" subtype="boardgame" collid="2">
+		<name sortindex="1">Ticket to Ride</name>
+		<status own="0" prevowned="0" fortrade="0" want="0" wanttoplay="0" wanttobuy="0" wishlist="1" wishlistpriority="1" preordered="0" lastmodified="2026-01-02 10:00:00" />
+		<wishlistcomment>Europe map is fine too</wishlistcomment>
+	</item>
+	<item objecttype="thing" objectid="314343" subtype="boardgameexpansion" collid="3">
+		<name sortindex="1">Cascadia: Landmarks</name>
+		<status own="0" prevowned="0" fortrade="0" want="0" wanttoplay="0" wanttobuy="1" wishlist="0" preordered="0" lastmodified="2026-01-02 10:00:00" />
+	</item>
+	<item objecttype="thing" objectid="13" subtype="boardgame" collid="4">
+		<name sortindex="1">CATAN</name>
+		<status own="0" prevowned="0" fortrade="0" want="0" wanttoplay="0" wanttobuy="0" wishlist="1" wishlistpriority="5" preordered="0" lastmodified="2026-01-02 10:00:00" />
+	</item>
+	<item objecttype="thing" objectid="30549" subtype="boardgame" collid="5">
+		<name sortindex="1">Pandemic</name>
+		<status own="1" prevowned="0" fortrade="0" want="0" wanttoplay="0" wanttobuy="0" wishlist="0" preordered="0" lastmodified="2026-01-02 10:00:00" />
+	</item>
+	<item objecttype="thing" objectid="295947" subtype="boardgame" collid="6">
+		<name sortindex="1">Cascadia</name>
+		<status own="0" prevowned="0" fortrade="0" want="0" wanttoplay="0" wanttobuy="0" wishlist="1" wishlistpriority="2" preordered="0" lastmodified="2026-01-02 10:00:00" />
+	</item>
+</items>`
+
+func TestMerge(t *testing.T) {
+	c, err := ParseCollection(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := []models.Game{
+		{Name: "Cascadia", Priority: 1, TargetPrice: 40},
+		{Name: "Wingspan", Priority: 2, BGGID: 266192},
+	}
+	res, err := Merge(existing, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Existing games keep their order and settings; new ones slot in by
+	// their BGG priority, after existing games of the same priority: the
+	// "Must have" goes second, the "Like to have" last. Owned and "Don't
+	// buy this" games are left out.
+	want := []models.Game{
+		{Name: "Cascadia", Priority: 1, TargetPrice: 40, BGGID: 295947},
+		{Name: "Ticket to Ride", Priority: 2, Starred: true, Notes: "Europe map is fine too", BGGID: 9209},
+		{Name: "Wingspan", Priority: 3, BGGID: 266192},
+		{Name: "Cascadia: Landmarks", Priority: 4, Expansion: true, BGGID: 314343},
+	}
+	if !reflect.DeepEqual(res.Games, want) {
+		t.Errorf("merged games:\n got  %+v\n want %+v", res.Games, want)
+	}
+	if res.Added != 2 || res.Linked != 1 || res.Skipped != 1 {
+		t.Errorf("added %d, linked %d, skipped %d; want 2, 1, 1", res.Added, res.Linked, res.Skipped)
+	}
+}
+
+func TestParseCollectionRejectsBGGMessages(t *testing.T) {
+	for _, tc := range []struct{ body, want string }{
+		{`<message>Your request for this collection has been accepted and will be processed.  Please try again later for access.</message>`, "Please try again later"},
+		{`<errors><error><message>Invalid username specified</message></error></errors>`, "Invalid username specified"},
+		{`{"items": []}`, "not a BGG collection export"},
+	} {
+		_, err := ParseCollection(strings.NewReader(tc.body))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseCollection(%.40q) error = %v, want one mentioning %q", tc.body, err, tc.want)
+		}
+	}
+}
+
+func TestItemGameKeepsQuotedComments(t *testing.T) {
+	c, err := ParseCollection(strings.NewReader(`<items><item objectid="1" subtype="boardgame">
+		<name>Cascadia</name>
+		<status wishlist="1" wishlistpriority="2" />
+		<wishlistcomment>Only the &quot;Big Box&quot;, not 'Lite' &amp; &lt;b&gt;promo&lt;/b&gt;</wishlistcomment>
+	</item></items>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Only the "Big Box", not 'Lite' & <b>promo</b>`
+	if got := c.Items[0].Game().Notes; got != want {
+		t.Errorf("Notes = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/utils"
+)
+
+const defaultCacheFile = "cache.json"
+
+// Entry is a cached store result
+type Entry struct {
+	Result    models.StoreResult `json:"result"`
+	StoredAt  time.Time          `json:"storedAt"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+}
+
+// Cache holds store results keyed by store ID and normalized query,
+// persisted to a JSON file so restarts keep it
+type Cache struct {
+	filepath string
+	mu       sync.Mutex
+	entries  map[string]Entry
+}
+
+// Open loads the cache file if it exists. A corrupt file is returned
+// as an error together with an empty, usable cache.
+func Open(filepath string) (*Cache, error) {
+	if filepath == "" {
+		filepath = defaultCacheFile
+	}
+	c := &Cache{
+		filepath: filepath,
+		entries:  make(map[string]Entry),
+	}
+
+	data, err := os.ReadFile(filepath)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		c.entries = make(map[string]Entry)
+		return c, err
+	}
+	return c, nil
+}
+
+// Key builds the cache key for a store and query key (see
+// stores.Query.CacheKey, which normalizes the search text). Keys differing
+// only in case or whitespace share an entry.
+func Key(storeID, query string) string {
+	return storeID + "|" + strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// Get returns the unexpired entry for key
+func (c *Cache) Get(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.ExpiresAt) {
+		return Entry{}, false
+	}
+	// Callers adjust match flags per game; keep the stored copy untouched
+	e.Result.Matches = append([]models.ProductMatch(nil), e.Result.Matches...)
+	return e, true
+}
+
+// Put stores a result for ttl
+func (c *Cache) Put(key string, result models.StoreResult, ttl time.Duration) {
+	now := time.Now()
+	result.Matches = append([]models.ProductMatch(nil), result.Matches...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = Entry{
+		Result:    result,
+		StoredAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+// Save drops expired entries and writes the cache to disk
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.ExpiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(c.filepath, data, 0644)
+}
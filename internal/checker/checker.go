@@ -1,17 +1,58 @@
 package checker
 
 import (
+	"context"
 	"sync"
 
+	"cardboard-hunter/internal/cache"
+	"cardboard-hunter/internal/config"
 	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/ratelimit"
 	"cardboard-hunter/internal/stores"
+	"cardboard-hunter/internal/utils"
 )
 
 // Checker handles game availability checking across multiple stores
 type Checker struct {
-	stores []stores.Store
+	stores  []stores.Store
+	cache   *cache.Cache
+	refresh bool
 }
 
+// limiters are shared by every Checker so that concurrent checks (a UI
+// check during a scheduled one) still respect each store's limits
+var limiters = struct {
+	sync.Mutex
+	byStore map[string]storeLimiter
+}{byStore: make(map[string]storeLimiter)}
+
+type storeLimiter struct {
+	cfg     config.RateLimitConfig
+	limiter *ratelimit.Limiter
+}
+
+// limiterFor returns the store's limiter, replacing it when the store's
+// rateLimit settings have changed
+func limiterFor(cfg *config.StoreConfig) *ratelimit.Limiter {
+	var rl config.RateLimitConfig
+	if cfg.RateLimit != nil {
+		rl = *cfg.RateLimit
+	}
+
+	limiters.Lock()
+	defer limiters.Unlock()
+	if sl, ok := limiters.byStore[cfg.ID]; ok && sl.cfg == rl {
+		return sl.limiter
+	}
+	l := ratelimit.New(rl.RequestsPerSecond, rl.MaxConcurrent, config.ParseDuration(rl.MinDelay, 0))
+	limiters.byStore[cfg.ID] = storeLimiter{cfg: rl, limiter: l}
+	return l
+}
+
+// ResultFunc is called as soon as a single store answers for a game.
+// It may be called concurrently from several goroutines.
+type ResultFunc func(gameIndex, storeIndex int, result models.StoreResult)
+
 // New creates a new Checker with all available stores
 func New() *Checker {
 	return &Checker{
@@ -19,10 +60,31 @@ func New() *Checker {
 	}
 }
 
-// CheckGame checks a single game across all stores concurrently
-func (c *Checker) CheckGame(gameName string) models.GameResult {
+// UseCache serves store results from rc while they are within each store's
+// cacheTTL. With refresh set, every store is queried and the cache updated.
+func (c *Checker) UseCache(rc *cache.Cache, refresh bool) {
+	c.cache = rc
+	c.refresh = refresh
+}
+
+// StoreNames returns the store names in the order used by GameResult.Results
+func (c *Checker) StoreNames() []string {
+	names := make([]string, len(c.stores))
+	for i, s := range c.stores {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// CheckGame checks a single game across all stores concurrently.
+// Cancelling ctx aborts every in-flight store request.
+func (c *Checker) CheckGame(ctx context.Context, game models.Game) models.GameResult {
+	return c.checkGame(ctx, 0, game, nil)
+}
+
+func (c *Checker) checkGame(ctx context.Context, gameIndex int, game models.Game, onResult ResultFunc) models.GameResult {
 	result := models.GameResult{
-		Name:    gameName,
+		Name:    game.Name,
 		Results: make([]models.StoreResult, len(c.stores)),
 	}
 
@@ -31,7 +93,12 @@ func (c *Checker) CheckGame(gameName string) models.GameResult {
 		wg.Add(1)
 		go func(idx int, s stores.Store) {
 			defer wg.Done()
-			result.Results[idx] = s.Check(gameName)
+			sr := c.checkStore(ctx, s, stores.QueryFor(game))
+			applyPreferences(game, &sr)
+			result.Results[idx] = sr
+			if onResult != nil {
+				onResult(gameIndex, idx, result.Results[idx])
+			}
 		}(i, store)
 	}
 	wg.Wait()
@@ -39,8 +106,41 @@ func (c *Checker) CheckGame(gameName string) models.GameResult {
 	return result
 }
 
+// checkStore searches a store under the game's name and aliases, going
+// through the cache when one is set. Requests to the store are paced by its
+// rate limiter.
+func (c *Checker) checkStore(ctx context.Context, s stores.Store, q stores.Query) models.StoreResult {
+	ctx = ratelimit.WithLimiter(ctx, limiterFor(s.Config()))
+
+	ttl := config.ParseDuration(s.Config().CacheTTL, 0)
+	if c.cache == nil || ttl <= 0 {
+		return stores.Search(ctx, s, q)
+	}
+
+	key := cache.Key(s.Config().ID, q.CacheKey())
+	if !c.refresh {
+		if e, ok := c.cache.Get(key); ok {
+			sr := e.Result
+			sr.CachedAt = &e.StoredAt
+			return sr
+		}
+	}
+
+	sr := stores.Search(ctx, s, q)
+	if sr.Error == "" {
+		c.cache.Put(key, sr, ttl)
+	}
+	return sr
+}
+
 // CheckGames checks multiple games with limited concurrency
-func (c *Checker) CheckGames(games []models.Game) []models.GameResult {
+func (c *Checker) CheckGames(ctx context.Context, games []models.Game) []models.GameResult {
+	return c.CheckGamesStream(ctx, games, nil)
+}
+
+// CheckGamesStream checks multiple games like CheckGames, calling onResult
+// for every store result the moment it is available
+func (c *Checker) CheckGamesStream(ctx context.Context, games []models.Game, onResult ResultFunc) []models.GameResult {
 	results := make([]models.GameResult, len(games))
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, 3) // Limit concurrent game checks
@@ -49,10 +149,14 @@ func (c *Checker) CheckGames(games []models.Game) []models.GameResult {
 		wg.Add(1)
 		go func(idx int, g models.Game) {
 			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+			// Once cancelled, stop queueing: stores return immediately with ctx.Err()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+			}
 
-			results[idx] = c.CheckGame(g.Name)
+			results[idx] = c.checkGame(ctx, idx, g, onResult)
 		}(i, game)
 	}
 
@@ -60,6 +164,49 @@ func (c *Checker) CheckGames(games []models.Game) []models.GameResult {
 	return results
 }
 
+// applyPreferences applies a game's wishlist settings to a store result:
+// matches are flagged against MaxPrice and TargetPrice, and a match in the
+// preferred language becomes the headline result when one exists
+func applyPreferences(game models.Game, sr *models.StoreResult) {
+	if !sr.Found {
+		return
+	}
+
+	for i := range sr.Matches {
+		m := &sr.Matches[i]
+		m.OverBudget = overBudget(game, m.PriceNum)
+		m.AtTarget = atTarget(game, m.PriceNum)
+	}
+
+	if game.PreferredLanguage != "" && utils.DetectLanguage(sr.Title) != game.PreferredLanguage {
+		for _, m := range sr.Matches {
+			if utils.DetectLanguage(m.Title) == game.PreferredLanguage {
+				sr.Title = m.Title
+				sr.URL = m.URL
+				sr.Price = m.Price
+				sr.PriceNum = m.PriceNum
+				sr.RegularPrice = m.RegularPrice
+				sr.DiscountPercent = m.DiscountPercent
+				sr.OnSale = m.OnSale
+				sr.InStock = m.InStock
+				sr.Confidence = m.Confidence
+				break
+			}
+		}
+	}
+
+	sr.OverBudget = overBudget(game, sr.PriceNum)
+	sr.AtTarget = atTarget(game, sr.PriceNum)
+}
+
+func overBudget(game models.Game, price float64) bool {
+	return game.MaxPrice > 0 && price > game.MaxPrice
+}
+
+func atTarget(game models.Game, price float64) bool {
+	return game.TargetPrice > 0 && price > 0 && price <= game.TargetPrice
+}
+
 // CalculateSummary calculates how many in-stock games each store has
 func (c *Checker) CalculateSummary(results []models.GameResult) map[string]int {
 	summary := make(map[string]int)
@@ -80,3 +227,42 @@ func (c *Checker) CalculateSummary(results []models.GameResult) map[string]int {
 
 	return summary
 }
+
+// CalculateSaleSummary counts, per store, the in-stock games sold at a discount
+func (c *Checker) CalculateSaleSummary(results []models.GameResult) map[string]int {
+	summary := make(map[string]int)
+
+	for _, store := range c.stores {
+		summary[store.Name()] = 0
+	}
+
+	for _, gr := range results {
+		for _, sr := range gr.Results {
+			if sr.Found && sr.InStock && sr.OnSale {
+				summary[sr.Store]++
+			}
+		}
+	}
+
+	return summary
+}
+
+// CalculateTargetSummary counts, per store, the in-stock games at or under
+// their target price
+func (c *Checker) CalculateTargetSummary(results []models.GameResult) map[string]int {
+	summary := make(map[string]int)
+
+	for _, store := range c.stores {
+		summary[store.Name()] = 0
+	}
+
+	for _, gr := range results {
+		for _, sr := range gr.Results {
+			if sr.Found && sr.InStock && sr.AtTarget {
+				summary[sr.Store]++
+			}
+		}
+	}
+
+	return summary
+}
@@ -33,9 +33,23 @@ func (l *Loader) LoadStoresConfig() (*StoresConfig, error) {
 	return &cfg, nil
 }
 
-// LoadStoreConfig loads an individual store's configuration
+// LoadSettings loads the application settings
+func (l *Loader) LoadSettings() (*Settings, error) {
+	data, err := l.readFile("settings.json")
+	if err != nil {
+		return nil, err
+	}
+	var cfg Settings
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LoadStoreConfig loads an individual store's configuration.
+// Builtin stores without a file return nil.
 func (l *Loader) LoadStoreConfig(ref StoreRef) (*StoreConfig, error) {
-	if ref.Builtin {
+	if ref.File == "" {
 		return nil, nil
 	}
 	data, err := l.readFile(ref.File)
@@ -1,12 +1,19 @@
 package config
 
+import "time"
+
 // StoreType represents the type of store checker
 type StoreType string
 
 const (
 	StoreTypeShopify     StoreType = "shopify"
 	StoreTypeHTMLScraper StoreType = "html_scraper"
+	StoreTypeSelector    StoreType = "html_selector"
+	StoreTypeStructured  StoreType = "structured_data" // schema.org JSON-LD / microdata
 	StoreTypeJSONAPI     StoreType = "json_api"
+	StoreTypeWooCommerce StoreType = "woocommerce"
+	StoreTypeMagento     StoreType = "magento_graphql"
+	StoreTypeBuiltin     StoreType = "builtin" // settings for a store implemented in Go
 )
 
 // StoresConfig is the main configuration file
@@ -25,26 +32,176 @@ type StoreRef struct {
 
 // DefaultConfig holds default settings
 type DefaultConfig struct {
-	MaxMatches int    `json:"maxMatches"`
-	Timeout    string `json:"timeout"`
+	MaxMatches   int    `json:"maxMatches"`
+	Timeout      string `json:"timeout"`
+	CacheTTL     string `json:"cacheTTL,omitempty"`     // e.g. "30m"; "0" disables caching
+	UserAgent    string `json:"userAgent,omitempty"`    // sent by stores that don't set their own
+	Retries      int    `json:"retries,omitempty"`      // retries of a failed request (timeouts, 429, 5xx)
+	RetryBackoff string `json:"retryBackoff,omitempty"` // delay before the first retry, doubled for each one
+
+	// Title patterns skipped at every store. ExpansionPatterns are only
+	// skipped for games not marked as an expansion. Both fall back to the
+	// built-in lists below when missing.
+	ExcludePatterns   []string `json:"excludePatterns,omitempty"`
+	ExpansionPatterns []string `json:"expansionPatterns,omitempty"`
+}
+
+// Built-in exclusion lists, used when stores.json doesn't set its own
+var (
+	DefaultExcludePatterns   = []string{"pre-order", "preorder", "précommande", "pré-commande"}
+	DefaultExpansionPatterns = []string{"expansion", "extension"}
+)
+
+// Settings holds application-wide settings (settings.json)
+type Settings struct {
+	Scheduler     SchedulerConfig     `json:"scheduler"`
+	Notifications NotificationsConfig `json:"notifications"`
+}
+
+// SchedulerConfig controls unattended wishlist checks
+type SchedulerConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Interval string `json:"interval"`         // e.g. "6h"
+	Jitter   string `json:"jitter,omitempty"` // random extra delay added to each interval
+}
+
+// DefaultSchedulerInterval is used when settings.json doesn't set an interval
+const DefaultSchedulerInterval = 6 * time.Hour
+
+// NotificationsConfig controls restock and price alerts
+type NotificationsConfig struct {
+	Enabled   bool                     `json:"enabled"`
+	Games     map[string]GameAlertRule `json:"games,omitempty"` // keyed by game name
+	Notifiers []NotifierConfig         `json:"notifiers,omitempty"`
+}
+
+// GameAlertRule holds per-game alert settings
+type GameAlertRule struct {
+	TargetPrice float64 `json:"targetPrice,omitempty"` // alert when the price drops to or below this
+	Restock     *bool   `json:"restock,omitempty"`     // alert on restock (default true)
+}
+
+// NotifierType represents the type of notifier
+type NotifierType string
+
+const (
+	NotifierWebhook NotifierType = "webhook"
+	NotifierEmail   NotifierType = "email"
+	NotifierDesktop NotifierType = "desktop"
+)
+
+// NotifierConfig configures a single notification target.
+// Empty Events, Games or Stores lists match everything.
+type NotifierConfig struct {
+	Type    NotifierType   `json:"type"`
+	Events  []string       `json:"events,omitempty"` // "restock", "price_target"
+	Games   []string       `json:"games,omitempty"`  // game names
+	Stores  []string       `json:"stores,omitempty"` // store names
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+	Email   *EmailConfig   `json:"email,omitempty"`
+}
+
+// WebhookConfig for JSON POST notifications
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Format  string            `json:"format,omitempty"` // "json" (default), "discord" or "slack"
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// EmailConfig for SMTP notifications
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// ParseDuration parses a duration string such as "15s" or "6h",
+// returning fallback when s is empty or invalid
+func ParseDuration(s string, fallback time.Duration) time.Duration {
+	if s == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return fallback
+	}
+	return d
 }
 
 // StoreConfig represents a single store's configuration
 type StoreConfig struct {
-	ID      string            `json:"id"`
-	Name    string            `json:"name"`
-	Enabled bool              `json:"enabled"`
-	Type    StoreType         `json:"type"`
-	BaseURL string            `json:"baseURL"`
-	Headers map[string]string `json:"headers,omitempty"`
-	Shopify *ShopifyConfig    `json:"shopify,omitempty"`
-	Scraper *ScraperConfig    `json:"scraper,omitempty"`
-	JSONAPI *JSONAPIConfig    `json:"jsonApi,omitempty"`
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Enabled      bool               `json:"enabled"`
+	Type         StoreType          `json:"type"`
+	BaseURL      string             `json:"baseURL"`
+	Headers      map[string]string  `json:"headers,omitempty"`
+	Cookies      map[string]string  `json:"cookies,omitempty"`     // e.g. {"currency": "CAD"}
+	QueryParams  map[string]string  `json:"queryParams,omitempty"` // added to every request URL
+	UserAgent    string             `json:"userAgent,omitempty"`   // overrides defaults.userAgent
+	CacheTTL     string             `json:"cacheTTL,omitempty"`    // overrides defaults.cacheTTL
+	MaxMatches   int                `json:"maxMatches,omitempty"`  // overrides defaults.maxMatches
+	RateLimit    *RateLimitConfig   `json:"rateLimit,omitempty"`
+	Retries      *int               `json:"retries,omitempty"`      // overrides defaults.retries
+	RetryBackoff string             `json:"retryBackoff,omitempty"` // overrides defaults.retryBackoff
+	Shopify      *ShopifyConfig     `json:"shopify,omitempty"`
+	WooCommerce  *WooCommerceConfig `json:"woocommerce,omitempty"`
+	Magento      *MagentoConfig     `json:"magento,omitempty"`
+	Scraper      *ScraperConfig     `json:"scraper,omitempty"`
+	Selector     *SelectorConfig    `json:"selector,omitempty"`
+	Structured   *StructuredConfig  `json:"structuredData,omitempty"`
+	JSONAPI      *JSONAPIConfig     `json:"jsonApi,omitempty"`
+
+	// Titles skipped at this store, on top of defaults.excludePatterns
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+
+	// Language the store lists games in ("en", "fr"): it is searched under
+	// the game's aliases in that language. Empty means every alias.
+	Language string `json:"language,omitempty"`
+
+	// Exclusion lists from stores.json defaults, set when the store is loaded.
+	// nil means the built-in lists.
+	GlobalExcludePatterns   []string `json:"-"`
+	GlobalExpansionPatterns []string `json:"-"`
+}
+
+// AllExcludePatterns returns the store's own exclude patterns, including
+// the legacy shopify.excludePatterns list
+func (c *StoreConfig) AllExcludePatterns() []string {
+	patterns := append([]string(nil), c.ExcludePatterns...)
+	if c.Shopify != nil {
+		patterns = append(patterns, c.Shopify.ExcludePatterns...)
+	}
+	return patterns
+}
+
+// RateLimitConfig keeps checks polite towards a store. Zero values mean no limit.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	MaxConcurrent     int     `json:"maxConcurrent,omitempty"` // requests in flight at once
+	MinDelay          string  `json:"minDelay,omitempty"`      // minimum time between two requests, e.g. "500ms"
 }
 
 // ShopifyConfig for Shopify-based stores
 type ShopifyConfig struct {
-	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+	ExcludePatterns []string `json:"excludePatterns,omitempty"` // deprecated: use the store's excludePatterns
+	Variants        bool     `json:"variants,omitempty"`        // look up each match's variants via /products/<handle>.js
+}
+
+// WooCommerceConfig for stores exposing the WooCommerce Store API.
+// It has no settings yet.
+type WooCommerceConfig struct{}
+
+// MagentoConfig for stores queried through the Magento 2 GraphQL API.
+// All fields are optional.
+type MagentoConfig struct {
+	GraphQLPath string `json:"graphqlPath,omitempty"` // default "/graphql"
+	StoreCode   string `json:"storeCode,omitempty"`   // sent as the Store header to pick a store view, e.g. "fr"
+	ProductPath string `json:"productPath,omitempty"` // prefix of product URLs, e.g. "/fr"
+	URLSuffix   string `json:"urlSuffix,omitempty"`   // appended to url_key, e.g. ".html"
 }
 
 // ScraperConfig for HTML scraping stores
@@ -54,12 +211,35 @@ type ScraperConfig struct {
 	TitlePatterns        []string       `json:"titlePatterns,omitempty"`
 	TitleGroups          CaptureGroups  `json:"titleGroups"`
 	PricePatterns        []PricePattern `json:"pricePatterns,omitempty"`
+	RegularPricePatterns []PricePattern `json:"regularPricePatterns,omitempty"` // struck-through "was" price on sale items
 	PricePrefix          string         `json:"pricePrefix"`
 	OutOfStockIndicators []string       `json:"outOfStockIndicators,omitempty"`
 	InStockIndicators    []string       `json:"inStockIndicators,omitempty"`
 	StockLogic           string         `json:"stockLogic,omitempty"` // "out_of_stock" (default) or "in_stock_required"
 }
 
+// SelectorConfig for HTML stores parsed with CSS selectors. Every selector
+// except card is matched inside a single product card.
+type SelectorConfig struct {
+	SearchPath       string `json:"searchPath"`
+	Card             string `json:"card"`                       // one element per product
+	Title            string `json:"title"`                      // element whose text is the title
+	Link             string `json:"link,omitempty"`             // element with the product href; defaults to the title element or its first link
+	Price            string `json:"price"`                      // element with the current price
+	PriceAttr        string `json:"priceAttr,omitempty"`        // read this attribute instead of the element text
+	RegularPrice     string `json:"regularPrice,omitempty"`     // struck-through "was" price on sale items
+	RegularPriceAttr string `json:"regularPriceAttr,omitempty"` // read this attribute instead of the element text
+	PricePrefix      string `json:"pricePrefix"`
+	OutOfStock       string `json:"outOfStock,omitempty"` // card is out of stock when this matches
+	InStock          string `json:"inStock,omitempty"`    // when set, only cards matching it are in stock
+}
+
+// StructuredConfig for stores whose search page embeds schema.org Product
+// data as JSON-LD or microdata
+type StructuredConfig struct {
+	SearchPath string `json:"searchPath"`
+}
+
 // CaptureGroups maps named captures to group indices
 type CaptureGroups struct {
 	URL   int `json:"url"`
@@ -89,9 +269,10 @@ type JSONAPIConfig struct {
 
 // JSONFieldMap maps product fields to JSON keys
 type JSONFieldMap struct {
-	Title       string `json:"title"`
-	Price       string `json:"price"`
-	URL         string `json:"url"`
-	Quantity    string `json:"quantity,omitempty"`
-	StockStatus string `json:"stockStatus,omitempty"`
+	Title        string `json:"title"`
+	Price        string `json:"price"`
+	RegularPrice string `json:"regularPrice,omitempty"` // undiscounted price, for sale detection
+	URL          string `json:"url"`
+	Quantity     string `json:"quantity,omitempty"`
+	StockStatus  string `json:"stockStatus,omitempty"`
 }
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+
+	"cardboard-hunter/internal/jsonpath"
+	"cardboard-hunter/internal/utils"
+)
+
+// DecodeStoreConfigStrict decodes a store config, rejecting unknown fields
+// so that typos such as "titlePattern" are reported instead of ignored
+func DecodeStoreConfigStrict(data []byte) (*StoreConfig, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var cfg StoreConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks a store config for missing fields and invalid patterns.
+// All problems are reported together, one per line.
+func (c *StoreConfig) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.ID == "" {
+		add("id is required")
+	}
+	if c.Name == "" {
+		add("name is required")
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		add("baseURL %q must be an absolute URL such as https://example.com", c.BaseURL)
+	} else if strings.HasSuffix(c.BaseURL, "/") {
+		add("baseURL %q must not end with a slash", c.BaseURL)
+	}
+
+	for name := range c.Cookies {
+		if name == "" || strings.ContainsAny(name, "=; \t") {
+			add("cookie name %q is invalid", name)
+		}
+	}
+	for name := range c.QueryParams {
+		if name == "" {
+			add("queryParams must not contain an empty name")
+		}
+	}
+
+	if c.Language != "" && !isLanguageCode(c.Language) {
+		add("language %q must be a two-letter code such as \"en\" or \"fr\"", c.Language)
+	}
+
+	if c.CacheTTL != "" {
+		if _, err := time.ParseDuration(c.CacheTTL); err != nil {
+			add("cacheTTL %q must be a duration such as \"30m\" or \"2h\"", c.CacheTTL)
+		}
+	}
+
+	if c.MaxMatches < 0 {
+		add("maxMatches must not be negative")
+	}
+	if c.Retries != nil && *c.Retries < 0 {
+		add("retries must not be negative")
+	}
+	if c.RetryBackoff != "" {
+		if d, err := time.ParseDuration(c.RetryBackoff); err != nil || d <= 0 {
+			add("retryBackoff %q must be a duration such as \"1s\"", c.RetryBackoff)
+		}
+	}
+
+	if rl := c.RateLimit; rl != nil {
+		if rl.RequestsPerSecond < 0 {
+			add("rateLimit.requestsPerSecond must not be negative")
+		}
+		if rl.MaxConcurrent < 0 {
+			add("rateLimit.maxConcurrent must not be negative")
+		}
+		if rl.MinDelay != "" {
+			if d, err := time.ParseDuration(rl.MinDelay); err != nil || d < 0 {
+				add("rateLimit.minDelay %q must be a duration such as \"500ms\"", rl.MinDelay)
+			}
+		}
+	}
+
+	validateTitlePatterns("excludePatterns", c.ExcludePatterns, add)
+	if c.Shopify != nil {
+		validateTitlePatterns("shopify.excludePatterns", c.Shopify.ExcludePatterns, add)
+	}
+
+	switch c.Type {
+	case StoreTypeShopify, StoreTypeWooCommerce, StoreTypeBuiltin:
+		// No required settings
+	case StoreTypeMagento:
+		if m := c.Magento; m != nil {
+			if m.GraphQLPath != "" && !strings.HasPrefix(m.GraphQLPath, "/") {
+				add("magento.graphqlPath %q must start with /", m.GraphQLPath)
+			}
+			if m.ProductPath != "" && (!strings.HasPrefix(m.ProductPath, "/") || strings.HasSuffix(m.ProductPath, "/")) {
+				add("magento.productPath %q must start with / and not end with one", m.ProductPath)
+			}
+		}
+	case StoreTypeHTMLScraper:
+		if c.Scraper == nil {
+			add("type %q requires a \"scraper\" section", c.Type)
+		} else {
+			errs = append(errs, c.Scraper.validate()...)
+		}
+	case StoreTypeSelector:
+		if c.Selector == nil {
+			add("type %q requires a \"selector\" section", c.Type)
+		} else {
+			errs = append(errs, c.Selector.validate()...)
+		}
+	case StoreTypeStructured:
+		if c.Structured == nil {
+			add("type %q requires a \"structuredData\" section", c.Type)
+		} else {
+			validateSearchPath("structuredData.searchPath", c.Structured.SearchPath, add)
+		}
+	case StoreTypeJSONAPI:
+		if c.JSONAPI == nil {
+			add("type %q requires a \"jsonApi\" section", c.Type)
+		} else {
+			errs = append(errs, c.JSONAPI.validate()...)
+		}
+	case "":
+		add("type is required")
+	default:
+		add("unknown type %q", c.Type)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the scheduler interval and jitter. An empty interval
+// means DefaultSchedulerInterval; a zero interval would check in a loop.
+func (c SchedulerConfig) Validate() error {
+	var errs []error
+	if c.Interval != "" {
+		if d, err := time.ParseDuration(c.Interval); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("scheduler.interval %q must be a positive duration such as \"6h\"", c.Interval))
+		}
+	}
+	if c.Jitter != "" {
+		if d, err := time.ParseDuration(c.Jitter); err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("scheduler.jitter %q must be a duration such as \"30m\"", c.Jitter))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ScraperConfig) validate() []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	validateSearchPath("scraper.searchPath", s.SearchPath, add)
+
+	if s.CardSplitter == "" {
+		add("scraper.cardSplitter is required")
+	} else if _, err := CompilePattern(s.CardSplitter); err != nil {
+		add("scraper.cardSplitter: %v", err)
+	}
+
+	if len(s.TitlePatterns) == 0 {
+		add("scraper.titlePatterns needs at least one pattern")
+	}
+	if s.TitleGroups.Title <= 0 || s.TitleGroups.URL <= 0 {
+		add("scraper.titleGroups.title and scraper.titleGroups.url must be capture group numbers (1 or more)")
+	}
+	for i, p := range s.TitlePatterns {
+		re, err := CompilePattern(p)
+		if err != nil {
+			add("scraper.titlePatterns[%d]: %v", i, err)
+			continue
+		}
+		if groups := re.NumSubexp(); s.TitleGroups.Title > groups || s.TitleGroups.URL > groups {
+			add("scraper.titlePatterns[%d] has %d capture groups but titleGroups uses title=%d url=%d",
+				i, groups, s.TitleGroups.Title, s.TitleGroups.URL)
+		}
+	}
+
+	validatePricePatterns("scraper.pricePatterns", s.PricePatterns, add)
+	validatePricePatterns("scraper.regularPricePatterns", s.RegularPricePatterns, add)
+
+	switch s.StockLogic {
+	case "", "out_of_stock":
+	case "in_stock_required":
+		if len(s.InStockIndicators) == 0 {
+			add("scraper.stockLogic \"in_stock_required\" needs at least one inStockIndicators entry")
+		}
+	default:
+		add("scraper.stockLogic %q must be \"out_of_stock\" or \"in_stock_required\"", s.StockLogic)
+	}
+
+	return errs
+}
+
+func (s *SelectorConfig) validate() []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	validateSearchPath("selector.searchPath", s.SearchPath, add)
+
+	for _, f := range []struct {
+		name     string
+		sel      string
+		required bool
+	}{
+		{"card", s.Card, true},
+		{"title", s.Title, true},
+		{"link", s.Link, false},
+		{"price", s.Price, true},
+		{"regularPrice", s.RegularPrice, false},
+		{"outOfStock", s.OutOfStock, false},
+		{"inStock", s.InStock, false},
+	} {
+		if f.sel == "" {
+			if f.required {
+				add("selector.%s is required", f.name)
+			}
+			continue
+		}
+		if _, err := CompileSelector(f.sel); err != nil {
+			add("selector.%s: %v", f.name, err)
+		}
+	}
+	if s.RegularPriceAttr != "" && s.RegularPrice == "" {
+		add("selector.regularPriceAttr is set but selector.regularPrice is empty")
+	}
+
+	return errs
+}
+
+func (j *JSONAPIConfig) validate() []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	validateSearchPath("jsonApi.searchPath", j.SearchPath, add)
+	if j.ProductsPath == "" {
+		add("jsonApi.productsPath is required (use \"$\" when the response is the product array)")
+	} else if _, err := jsonpath.Parse(j.ProductsPath); err != nil {
+		add("jsonApi.productsPath: %v", err)
+	}
+	if j.Fields.Title == "" {
+		add("jsonApi.fields.title is required")
+	}
+	if j.Fields.Price == "" {
+		add("jsonApi.fields.price is required")
+	}
+	if j.Fields.URL == "" {
+		add("jsonApi.fields.url is required")
+	}
+	for _, f := range [][2]string{
+		{"title", j.Fields.Title},
+		{"price", j.Fields.Price},
+		{"regularPrice", j.Fields.RegularPrice},
+		{"url", j.Fields.URL},
+		{"quantity", j.Fields.Quantity},
+		{"stockStatus", j.Fields.StockStatus},
+	} {
+		if f[1] == "" {
+			continue
+		}
+		if _, err := jsonpath.Parse(f[1]); err != nil {
+			add("jsonApi.fields.%s: %v", f[0], err)
+		}
+	}
+	if j.Fields.StockStatus != "" && j.InStockValue == "" {
+		add("jsonApi.fields.stockStatus is set but jsonApi.inStockValue is empty")
+	}
+
+	return errs
+}
+
+func validatePricePatterns(field string, patterns []PricePattern, add func(string, ...any)) {
+	for i, pp := range patterns {
+		re, err := CompilePattern(pp.Pattern)
+		if err != nil {
+			add("%s[%d]: %v", field, i, err)
+			continue
+		}
+		groups := re.NumSubexp()
+		g := pp.Groups
+		switch {
+		case g.Amount > 0:
+			if g.Amount > groups {
+				add("%s[%d].groups.amount=%d but the pattern has %d capture groups", field, i, g.Amount, groups)
+			}
+		case g.Dollars > 0 && g.Cents > 0:
+			if g.Dollars > groups || g.Cents > groups {
+				add("%s[%d].groups dollars=%d cents=%d but the pattern has %d capture groups",
+					field, i, g.Dollars, g.Cents, groups)
+			}
+		default:
+			add("%s[%d].groups needs \"amount\" or both \"dollars\" and \"cents\"", field, i)
+		}
+	}
+}
+
+// isLanguageCode reports whether s is a lowercase ISO 639-1 code
+func isLanguageCode(s string) bool {
+	return len(s) == 2 && s[0] >= 'a' && s[0] <= 'z' && s[1] >= 'a' && s[1] <= 'z'
+}
+
+func validateTitlePatterns(field string, patterns []string, add func(string, ...any)) {
+	for i, p := range patterns {
+		if err := utils.ValidateTitlePattern(p); err != nil {
+			add("%s[%d]: %v", field, i, err)
+		}
+	}
+}
+
+func validateSearchPath(field, path string, add func(string, ...any)) {
+	switch {
+	case path == "":
+		add("%s is required", field)
+	case !strings.Contains(path, "{query}"):
+		add("%s %q must contain the {query} placeholder", field, path)
+	case !strings.HasPrefix(path, "/"):
+		add("%s %q must start with /", field, path)
+	}
+}
+
+// CompileSelector compiles a config CSS selector (comma-separated groups
+// allowed), wrapping errors with the offending selector
+func CompileSelector(selector string) (cascadia.Matcher, error) {
+	sel, err := cascadia.ParseGroup(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %v", selector, err)
+	}
+	return sel, nil
+}
+
+// CompilePattern compiles a config regex, wrapping syntax errors
+// with the offending pattern
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("invalid pattern %q: %s (near %q)", pattern, syntaxErr.Code, syntaxErr.Expr)
+		}
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestSchedulerConfigValidate(t *testing.T) {
+	tests := []struct {
+		interval, jitter string
+		ok               bool
+	}{
+		{"", "", true},
+		{"6h", "30m", true},
+		{"6h", "0s", true},
+		{"0s", "", false},
+		{"-1h", "", false},
+		{"soon", "", false},
+		{"6h", "-5m", false},
+	}
+	for _, tt := range tests {
+		err := SchedulerConfig{Enabled: true, Interval: tt.interval, Jitter: tt.jitter}.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("interval %q jitter %q: Validate() = %v, want ok %t", tt.interval, tt.jitter, err, tt.ok)
+		}
+	}
+}
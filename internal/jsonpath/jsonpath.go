@@ -0,0 +1,161 @@
+// Package jsonpath evaluates the small path language used by json_api store
+// configs against decoded JSON (map[string]any / []any values).
+//
+// A path is a chain of keys and indexes such as "data.items[0].attributes.price".
+// "[*]" (or "[]") expands every element of an array, keys containing dots can
+// be quoted as ['key.name'], and a leading "$" for the document root is optional.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Path is a parsed path expression
+type Path []segment
+
+// Parse parses a path expression. An empty path or "$" refers to the root.
+func Parse(expr string) (Path, error) {
+	s := strings.TrimSpace(expr)
+	s = strings.TrimPrefix(s, "$")
+	s = strings.TrimPrefix(s, ".")
+
+	var p Path
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			if i == len(s)-1 || s[i+1] == '.' || s[i+1] == '[' {
+				return nil, fmt.Errorf("path %q: empty key at position %d", expr, i+1)
+			}
+			i++
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: missing ]", expr)
+			}
+			inner := s[i+1 : i+end]
+			seg, err := parseBracket(inner)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %v", expr, err)
+			}
+			p = append(p, seg)
+			i += end + 1
+			if i < len(s) && s[i] != '.' && s[i] != '[' {
+				return nil, fmt.Errorf("path %q: expected . or [ after ] at position %d", expr, i+1)
+			}
+		default:
+			end := strings.IndexAny(s[i:], ".[")
+			if end < 0 {
+				end = len(s) - i
+			}
+			p = append(p, segment{key: s[i : i+end]})
+			i += end
+		}
+	}
+	return p, nil
+}
+
+func parseBracket(inner string) (segment, error) {
+	switch {
+	case inner == "" || inner == "*":
+		return segment{wildcard: true}, nil
+	case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+		return segment{key: inner[1 : len(inner)-1]}, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil || n < 0 {
+		return segment{}, fmt.Errorf("[%s] must be an index, * or a quoted key", inner)
+	}
+	return segment{index: n, isIndex: true}, nil
+}
+
+// All returns every value the path selects; wildcards may select several
+func (p Path) All(v any) []any {
+	vals := []any{v}
+	for _, seg := range p {
+		var next []any
+		for _, cur := range vals {
+			next = append(next, seg.apply(cur)...)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		vals = next
+	}
+	return vals
+}
+
+// Get returns the first value the path selects
+func (p Path) Get(v any) (any, bool) {
+	vals := p.All(v)
+	if len(vals) == 0 {
+		return nil, false
+	}
+	return vals[0], true
+}
+
+func (s segment) apply(v any) []any {
+	switch {
+	case s.wildcard:
+		arr, _ := v.([]any)
+		return arr
+	case s.isIndex:
+		arr, ok := v.([]any)
+		if !ok || s.index >= len(arr) {
+			return nil
+		}
+		return []any{arr[s.index]}
+	default:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		val, ok := m[s.key]
+		if !ok {
+			return nil
+		}
+		return []any{val}
+	}
+}
+
+// String returns the value at path as a string. Numbers and booleans are
+// formatted, so a numeric price 49.9 becomes "49.9".
+func (p Path) String(v any) string {
+	val, ok := p.Get(v)
+	if !ok {
+		return ""
+	}
+	switch x := val.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return ""
+}
+
+// Number returns the value at path as a number. Numeric strings are parsed.
+func (p Path) Number(v any) (float64, bool) {
+	val, ok := p.Get(v)
+	if !ok {
+		return 0, false
+	}
+	switch x := val.(type) {
+	case float64:
+		return x, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
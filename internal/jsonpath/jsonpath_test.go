@@ -0,0 +1,103 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const doc = `{
+	"data": {
+		"items": [
+			{"name": "Cascadia", "price": 49.99, "stock": "12", "tags": ["family", "tiles"], "prices.cad": {"amount": "54.99"}},
+			{"name": "Wingspan", "price": "64.95", "stock": true, "tags": []}
+		],
+		"total": 2
+	}
+}`
+
+func decode(t *testing.T) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"data..items",
+		"data.",
+		"data.[0]",
+		"data.items[0",
+		"data.items[-1]",
+		"data.items[x]",
+		"data.items[0]name",
+		"data.items['prices.cad']amount",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) = nil error, want one", expr)
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	v := decode(t)
+	tests := []struct {
+		path string
+		want []any
+	}{
+		{"", []any{v}},
+		{"$.data.total", []any{2.0}},
+		{"data.items[1].name", []any{"Wingspan"}},
+		{"data.items[*].name", []any{"Cascadia", "Wingspan"}},
+		{"data.items[].tags[0]", []any{"family"}},
+		{"data.items[0]['prices.cad'].amount", []any{"54.99"}},
+		{`data["items"][0].tags[1]`, []any{"tiles"}},
+		{"data.items[2].name", nil},
+		{"data.total.value", nil},
+		{"data.items.name", nil},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.path)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.path, err)
+			continue
+		}
+		if got := p.All(v); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q selects %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestStringAndNumber(t *testing.T) {
+	v := decode(t)
+	tests := []struct {
+		path  string
+		str   string
+		num   float64
+		numOK bool
+	}{
+		{"data.items[0].name", "Cascadia", 0, false},
+		{"data.items[0].price", "49.99", 49.99, true},
+		{"data.items[1].price", "64.95", 64.95, true},
+		{"data.items[0].stock", "12", 12, true},
+		{"data.items[1].stock", "true", 0, false},
+		{"data.items[0].tags", "", 0, false}, // an array
+		{"data.items[0].missing", "", 0, false},
+		{"data.items[5].price", "", 0, false},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.path)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.path, err)
+		}
+		if got := p.String(v); got != tt.str {
+			t.Errorf("String(%q) = %q, want %q", tt.path, got, tt.str)
+		}
+		if got, ok := p.Number(v); got != tt.num || ok != tt.numOK {
+			t.Errorf("Number(%q) = %v, %t; want %v, %t", tt.path, got, ok, tt.num, tt.numOK)
+		}
+	}
+}
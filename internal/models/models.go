@@ -1,34 +1,93 @@
 package models
 
+import (
+	"math"
+	"time"
+)
+
 // Game represents a board game from the user's wishlist
 type Game struct {
-	Name     string `json:"name"`
-	Priority int    `json:"priority"`
-	Starred  bool   `json:"starred"`
+	Name              string  `json:"name"`
+	Priority          int     `json:"priority"`
+	Starred           bool    `json:"starred"`
+	TargetPrice       float64 `json:"targetPrice,omitempty"` // highlight results at or under this price
+	MaxPrice          float64 `json:"maxPrice,omitempty"`    // flag matches above this price
+	Notes             string  `json:"notes,omitempty"`
+	PreferredLanguage string  `json:"preferredLanguage,omitempty"` // "en" or "fr"
+	BGGID             int     `json:"bggId,omitempty"`             // BoardGameGeek thing ID, set by the BGG import
+
+	// Other names the game is sold under, by language, e.g.
+	// {"fr": ["Les Aventuriers du Rail"]}
+	Aliases map[string][]string `json:"aliases,omitempty"`
+
+	// Title rules: keywords, or regular expressions written as /.../
+	Include   []string `json:"include,omitempty"`   // every matching title must contain these
+	Exclude   []string `json:"exclude,omitempty"`   // titles containing any of these are skipped
+	Expansion bool     `json:"expansion,omitempty"` // the game is an expansion: don't skip titles saying "expansion"
 }
 
 // ProductMatch represents a single matching product from a store
 type ProductMatch struct {
-	Title    string  `json:"title"`
-	Price    string  `json:"price"`
-	PriceNum float64 `json:"priceNum"`
-	URL      string  `json:"url"`
-	InStock  bool    `json:"inStock"`
+	Title           string  `json:"title"`
+	Price           string  `json:"price"`
+	PriceNum        float64 `json:"priceNum"`
+	RegularPrice    float64 `json:"regularPrice,omitempty"` // price before discount, when the store shows one
+	DiscountPercent int     `json:"discountPercent,omitempty"`
+	OnSale          bool    `json:"onSale,omitempty"`
+	URL             string  `json:"url"`
+	InStock         bool    `json:"inStock"`
+	Confidence      float64 `json:"confidence"` // 0-1 relevance of the title to the search
+	OverBudget      bool    `json:"overBudget,omitempty"`
+	AtTarget        bool    `json:"atTarget,omitempty"`
+}
+
+// SetRegularPrice records the undiscounted price. The match is on sale
+// when regular is above the current price.
+func (m *ProductMatch) SetRegularPrice(regular float64) {
+	if regular <= m.PriceNum || m.PriceNum <= 0 {
+		return
+	}
+	m.RegularPrice = regular
+	m.DiscountPercent = int(math.Round((regular - m.PriceNum) / regular * 100))
+	m.OnSale = true
 }
 
 // StoreResult represents the availability result from a single store
 type StoreResult struct {
-	Store    string         `json:"store"`
-	Found    bool           `json:"found"`
-	InStock  bool           `json:"inStock"`
-	Price    string         `json:"price"`
-	PriceNum float64        `json:"priceNum"`
-	URL      string         `json:"url"`
-	Title    string         `json:"title"`
-	Error    string         `json:"error,omitempty"`
-	Matches  []ProductMatch `json:"matches,omitempty"`
+	Store           string         `json:"store"`
+	Found           bool           `json:"found"`
+	InStock         bool           `json:"inStock"`
+	Price           string         `json:"price"`
+	PriceNum        float64        `json:"priceNum"`
+	RegularPrice    float64        `json:"regularPrice,omitempty"`
+	DiscountPercent int            `json:"discountPercent,omitempty"`
+	OnSale          bool           `json:"onSale,omitempty"`
+	URL             string         `json:"url"`
+	Title           string         `json:"title"`
+	Confidence      float64        `json:"confidence,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	ErrorKind       ErrorKind      `json:"errorKind,omitempty"`
+	Matches         []ProductMatch `json:"matches,omitempty"`
+	OverBudget      bool           `json:"overBudget,omitempty"`
+	AtTarget        bool           `json:"atTarget,omitempty"`
+	CachedAt        *time.Time     `json:"cachedAt,omitempty"` // set when served from the result cache
 }
 
+// ErrorKind classifies why a store check failed
+type ErrorKind string
+
+const (
+	ErrorTimeout   ErrorKind = "timeout"   // the store did not answer in time
+	ErrorNetwork   ErrorKind = "network"   // the connection failed
+	ErrorBlocked   ErrorKind = "blocked"   // HTTP 403 or 429: the store refused our requests
+	ErrorHTTP4xx   ErrorKind = "http_4xx"  // other client errors, e.g. a moved search page
+	ErrorHTTP5xx   ErrorKind = "http_5xx"  // the store is down or failing
+	ErrorParse     ErrorKind = "parse"     // the response was not in the expected format
+	ErrorStore     ErrorKind = "store"     // the store reported an error, e.g. a GraphQL error
+	ErrorConfig    ErrorKind = "config"    // the store config cannot be used
+	ErrorCancelled ErrorKind = "cancelled" // the check was cancelled
+)
+
 // GameResult represents all store results for a single game
 type GameResult struct {
 	Name    string        `json:"name"`
@@ -37,11 +96,71 @@ type GameResult struct {
 
 // CheckRequest is the API request format
 type CheckRequest struct {
-	Games []Game `json:"games"`
+	Games   []Game `json:"games"`
+	Refresh bool   `json:"refresh,omitempty"` // bypass the result cache
 }
 
 // CheckResponse is the API response format
 type CheckResponse struct {
-	Results []GameResult   `json:"results"`
-	Summary map[string]int `json:"summary"`
+	Results   []GameResult   `json:"results"`
+	Summary   map[string]int `json:"summary"`
+	AtTarget  map[string]int `json:"atTarget"` // in-stock games at or under their target price, per store
+	OnSale    map[string]int `json:"onSale"`   // in-stock games sold at a discount, per store
+	CheckedAt time.Time      `json:"checkedAt"`
+}
+
+// StreamStart is the first event sent by the streaming check endpoint
+type StreamStart struct {
+	Job    uint64   `json:"job"`
+	Games  []string `json:"games"`
+	Stores []string `json:"stores"`
+}
+
+// StreamResult is sent whenever a single store answers for a game
+type StreamResult struct {
+	GameIndex  int         `json:"gameIndex"`
+	StoreIndex int         `json:"storeIndex"`
+	Result     StoreResult `json:"result"`
+}
+
+// StreamProgress reports how many store checks have completed
+type StreamProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// StreamComplete is the final event of a streaming check
+type StreamComplete struct {
+	Summary   map[string]int `json:"summary"`
+	AtTarget  map[string]int `json:"atTarget"`
+	OnSale    map[string]int `json:"onSale"`
+	Cancelled bool           `json:"cancelled,omitempty"`
+}
+
+// PricePoint is a single product observation recorded during a check
+type PricePoint struct {
+	Time     time.Time `json:"time"`
+	Game     string    `json:"game"`
+	Store    string    `json:"store"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url"`
+	Price    string    `json:"price"`
+	PriceNum float64   `json:"priceNum"`
+	InStock  bool      `json:"inStock"`
+}
+
+// HistoryResponse is the price history API response format
+type HistoryResponse struct {
+	Game   string       `json:"game"`
+	Points []PricePoint `json:"points"`
+}
+
+// ScheduleStatus reports the state of the background scheduler
+type ScheduleStatus struct {
+	Enabled   bool       `json:"enabled"`
+	Interval  string     `json:"interval,omitempty"`
+	Running   bool       `json:"running"`
+	LastRun   *time.Time `json:"lastRun,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	NextRun   *time.Time `json:"nextRun,omitempty"`
 }
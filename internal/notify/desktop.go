@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// DesktopNotifier shows a native desktop notification per alert
+type DesktopNotifier struct{}
+
+// NewDesktopNotifier creates a desktop notifier
+func NewDesktopNotifier() *DesktopNotifier {
+	return &DesktopNotifier{}
+}
+
+func (n *DesktopNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	for _, a := range alerts {
+		if err := showNotification(ctx, "Cardboard Hunter", a.Message()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func showNotification(ctx context.Context, title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(10000, '%s', '%s', 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, psQuote(title), psQuote(message))
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script)
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", title, message)
+	}
+	return cmd.Run()
+}
+
+// psQuote escapes a string for a single-quoted PowerShell literal
+func psQuote(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
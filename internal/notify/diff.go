@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+)
+
+// AlertKind identifies what changed between two checks
+type AlertKind string
+
+const (
+	AlertRestock     AlertKind = "restock"
+	AlertPriceTarget AlertKind = "price_target"
+)
+
+// Alert describes a single change worth notifying about
+type Alert struct {
+	Kind          AlertKind `json:"kind"`
+	Game          string    `json:"game"`
+	Store         string    `json:"store"`
+	Title         string    `json:"title"`
+	URL           string    `json:"url"`
+	Price         string    `json:"price"`
+	PriceNum      float64   `json:"priceNum"`
+	PreviousPrice float64   `json:"previousPrice,omitempty"`
+	TargetPrice   float64   `json:"targetPrice,omitempty"`
+	InStock       bool      `json:"inStock"`
+}
+
+// Message returns a one-line human readable description of the alert
+func (a Alert) Message() string {
+	switch a.Kind {
+	case AlertRestock:
+		return fmt.Sprintf("%s is back in stock at %s for %s", a.Game, a.Store, a.Price)
+	case AlertPriceTarget:
+		stock := "in stock"
+		if !a.InStock {
+			stock = "out of stock"
+		}
+		return fmt.Sprintf("%s is %s at %s (target $%.2f, %s)", a.Game, a.Price, a.Store, a.TargetPrice, stock)
+	}
+	return fmt.Sprintf("%s changed at %s", a.Game, a.Store)
+}
+
+// Diff compares two consecutive result sets and returns the alerts triggered
+// by curr. Stores that errored in either check are ignored so a flaky store
+// does not produce spurious restocks.
+func Diff(prev, curr []models.GameResult, rules map[string]config.GameAlertRule) []Alert {
+	prevByGame := make(map[string]map[string]models.StoreResult)
+	for _, gr := range prev {
+		stores := make(map[string]models.StoreResult)
+		for _, sr := range gr.Results {
+			stores[sr.Store] = sr
+		}
+		prevByGame[strings.ToLower(gr.Name)] = stores
+	}
+
+	var alerts []Alert
+	for _, gr := range curr {
+		prevStores, ok := prevByGame[strings.ToLower(gr.Name)]
+		if !ok {
+			continue // newly added game: nothing to compare against
+		}
+		rule := findRule(rules, gr.Name)
+
+		for _, sr := range gr.Results {
+			before, ok := prevStores[sr.Store]
+			if !ok || before.Error != "" || sr.Error != "" || !sr.Found {
+				continue
+			}
+
+			alert := Alert{
+				Game:     gr.Name,
+				Store:    sr.Store,
+				Title:    sr.Title,
+				URL:      sr.URL,
+				Price:    sr.Price,
+				PriceNum: sr.PriceNum,
+				InStock:  sr.InStock,
+			}
+			if before.Found {
+				alert.PreviousPrice = before.PriceNum
+			}
+
+			if rule.Restock == nil || *rule.Restock {
+				if sr.InStock && !(before.Found && before.InStock) {
+					a := alert
+					a.Kind = AlertRestock
+					alerts = append(alerts, a)
+				}
+			}
+
+			if target := rule.TargetPrice; target > 0 && sr.PriceNum > 0 && sr.PriceNum <= target {
+				wasAtTarget := before.Found && before.PriceNum > 0 && before.PriceNum <= target
+				if !wasAtTarget {
+					a := alert
+					a.Kind = AlertPriceTarget
+					a.TargetPrice = target
+					alerts = append(alerts, a)
+				}
+			}
+		}
+	}
+	return alerts
+}
+
+func findRule(rules map[string]config.GameAlertRule, game string) config.GameAlertRule {
+	if r, ok := rules[game]; ok {
+		return r
+	}
+	for name, r := range rules {
+		if strings.EqualFold(name, game) {
+			return r
+		}
+	}
+	return config.GameAlertRule{}
+}
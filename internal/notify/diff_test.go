@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"reflect"
+	"testing"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	noRestock := false
+	sold := func(price float64, inStock bool) models.StoreResult {
+		return models.StoreResult{Store: "Bliss", Found: true, Title: "Cascadia", PriceNum: price, InStock: inStock}
+	}
+	tests := []struct {
+		name        string
+		before, now models.StoreResult
+		rule        config.GameAlertRule
+		want        []AlertKind
+	}{
+		{"restock", sold(49.99, false), sold(49.99, true), config.GameAlertRule{}, []AlertKind{AlertRestock}},
+		{"newly listed", models.StoreResult{Store: "Bliss"}, sold(49.99, true), config.GameAlertRule{}, []AlertKind{AlertRestock}},
+		{"restock alerts off", sold(49.99, false), sold(49.99, true), config.GameAlertRule{Restock: &noRestock}, nil},
+		{"price drop to target", sold(49.99, true), sold(39.99, true), config.GameAlertRule{TargetPrice: 40}, []AlertKind{AlertPriceTarget}},
+		{"restock already at target", sold(39.99, false), sold(39.99, true), config.GameAlertRule{TargetPrice: 40}, []AlertKind{AlertRestock}},
+		{"already at target", sold(39.99, true), sold(38.99, true), config.GameAlertRule{TargetPrice: 40}, nil},
+		{"above target", sold(49.99, true), sold(44.99, true), config.GameAlertRule{TargetPrice: 40}, nil},
+		{"no change", sold(49.99, true), sold(49.99, true), config.GameAlertRule{TargetPrice: 40}, nil},
+		{"sold out", sold(49.99, true), sold(49.99, false), config.GameAlertRule{}, nil},
+		{"store failed before", models.StoreResult{Store: "Bliss", Error: "timeout"}, sold(49.99, true), config.GameAlertRule{}, nil},
+		{"store failing now", sold(49.99, false), models.StoreResult{Store: "Bliss", Error: "timeout"}, config.GameAlertRule{}, nil},
+	}
+	for _, tt := range tests {
+		prev := []models.GameResult{{Name: "Cascadia", Results: []models.StoreResult{tt.before}}}
+		curr := []models.GameResult{{Name: "cascadia", Results: []models.StoreResult{tt.now}}}
+		rules := map[string]config.GameAlertRule{"Cascadia": tt.rule}
+
+		var got []AlertKind
+		for _, a := range Diff(prev, curr, rules) {
+			got = append(got, a.Kind)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: alerts %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffSkipsNewGames(t *testing.T) {
+	curr := []models.GameResult{{Name: "Wingspan", Results: []models.StoreResult{{Store: "Bliss", Found: true, InStock: true}}}}
+	if alerts := Diff(nil, curr, nil); len(alerts) != 0 {
+		t.Errorf("Diff against no previous results = %v, want no alerts", alerts)
+	}
+}
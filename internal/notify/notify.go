@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cardboard-hunter/internal/config"
+)
+
+// Notifier delivers alerts to a single destination
+type Notifier interface {
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
+// Dispatcher fans alerts out to every configured notifier,
+// applying each notifier's event, game and store filters
+type Dispatcher struct {
+	targets []target
+}
+
+type target struct {
+	notifier Notifier
+	cfg      config.NotifierConfig
+}
+
+// NewDispatcher creates notifiers from configuration
+func NewDispatcher(cfg config.NotificationsConfig) (*Dispatcher, error) {
+	d := &Dispatcher{}
+	for i, nc := range cfg.Notifiers {
+		n, err := newNotifier(nc)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %d: %w", i+1, err)
+		}
+		d.targets = append(d.targets, target{notifier: n, cfg: nc})
+	}
+	return d, nil
+}
+
+func newNotifier(cfg config.NotifierConfig) (Notifier, error) {
+	switch cfg.Type {
+	case config.NotifierWebhook:
+		if cfg.Webhook == nil || cfg.Webhook.URL == "" {
+			return nil, errors.New("webhook notifier requires webhook.url")
+		}
+		return NewWebhookNotifier(*cfg.Webhook), nil
+	case config.NotifierEmail:
+		if cfg.Email == nil || cfg.Email.Host == "" || len(cfg.Email.To) == 0 {
+			return nil, errors.New("email notifier requires email.host and email.to")
+		}
+		return NewEmailNotifier(*cfg.Email), nil
+	case config.NotifierDesktop:
+		return NewDesktopNotifier(), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", cfg.Type)
+	}
+}
+
+// Dispatch sends alerts to every notifier whose filters match.
+// All notifiers are attempted; their errors are joined.
+func (d *Dispatcher) Dispatch(ctx context.Context, alerts []Alert) error {
+	var errs []error
+	for _, t := range d.targets {
+		var selected []Alert
+		for _, a := range alerts {
+			if matches(t.cfg, a) {
+				selected = append(selected, a)
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+		if err := t.notifier.Notify(ctx, selected); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.cfg.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func matches(cfg config.NotifierConfig, a Alert) bool {
+	return containsFold(cfg.Events, string(a.Kind)) &&
+		containsFold(cfg.Games, a.Game) &&
+		containsFold(cfg.Stores, a.Store)
+}
+
+// containsFold reports whether list contains s; an empty list matches anything
+func containsFold(list []string, s string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryLines formats alerts as one message line each, with links
+func summaryLines(alerts []Alert) []string {
+	lines := make([]string, len(alerts))
+	for i, a := range alerts {
+		lines[i] = a.Message()
+		if a.URL != "" {
+			lines[i] += " — " + a.URL
+		}
+	}
+	return lines
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cardboard-hunter/internal/config"
+)
+
+// discordContentLimit is Discord's maximum message length
+const discordContentLimit = 2000
+
+// WebhookNotifier POSTs alerts as JSON. The "discord" and "slack" formats
+// produce payloads those services accept on their incoming webhooks.
+type WebhookNotifier struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier from config
+func NewWebhookNotifier(cfg config.WebhookConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	payload, err := json.Marshal(n.payload(alerts))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) payload(alerts []Alert) any {
+	text := "🎲 Cardboard Hunter\n" + strings.Join(summaryLines(alerts), "\n")
+
+	switch n.cfg.Format {
+	case "discord":
+		// The limit counts characters, and cutting bytes could split one
+		if runes := []rune(text); len(runes) > discordContentLimit {
+			text = string(runes[:discordContentLimit-1]) + "…"
+		}
+		return map[string]string{"content": text}
+	case "slack":
+		return map[string]string{"text": text}
+	default:
+		return map[string]any{"alerts": alerts}
+	}
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"cardboard-hunter/internal/config"
+)
+
+func TestDiscordPayloadTruncatesByCharacter(t *testing.T) {
+	var alerts []Alert
+	for i := 0; i < 100; i++ {
+		alerts = append(alerts, Alert{Kind: AlertRestock, Game: "Les Aventuriers du Rail : Europe", Store: "Le Valet d'Cœur", Price: "54,99 €"})
+	}
+
+	n := NewWebhookNotifier(config.WebhookConfig{Format: "discord"})
+	content := n.payload(alerts).(map[string]string)["content"]
+
+	if !utf8.ValidString(content) {
+		t.Fatal("truncated content is not valid UTF-8")
+	}
+	if got := utf8.RuneCountInString(content); got != discordContentLimit {
+		t.Errorf("content has %d characters, want %d", got, discordContentLimit)
+	}
+	if !strings.HasSuffix(content, "…") {
+		t.Errorf("truncated content should end with an ellipsis, got %q", content[len(content)-10:])
+	}
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limiter spaces out and caps concurrent requests to a single store, and
+// holds back every request while the store has asked us to slow down
+type Limiter struct {
+	sem      chan struct{} // nil when concurrency is unlimited
+	interval time.Duration // minimum time between request starts
+
+	mu          sync.Mutex
+	next        time.Time // earliest start of the next request
+	pausedUntil time.Time
+}
+
+// New creates a limiter. Zero values disable the matching limit; the
+// spacing between requests is the larger of 1/perSecond and minDelay.
+func New(perSecond float64, maxConcurrent int, minDelay time.Duration) *Limiter {
+	l := &Limiter{interval: minDelay}
+	if perSecond > 0 {
+		if d := time.Duration(float64(time.Second) / perSecond); d > l.interval {
+			l.interval = d
+		}
+	}
+	if maxConcurrent > 0 {
+		l.sem = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// Wait blocks until a request may start. The returned release function
+// must be called once the request has finished.
+func (l *Limiter) Wait(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+			release = func() { <-l.sem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := now
+	if l.next.After(start) {
+		start = l.next
+	}
+	if l.pausedUntil.After(start) {
+		start = l.pausedUntil
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// Pause holds back requests that have not started yet for d
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+type contextKey struct{}
+
+// WithLimiter returns a context whose store requests go through l
+func WithLimiter(ctx context.Context, l *Limiter) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the limiter attached to ctx, or nil
+func FromContext(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(contextKey{}).(*Limiter)
+	return l
+}
+
+// RetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date. It returns false when the header is missing or invalid.
+func RetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
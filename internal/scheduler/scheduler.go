@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"cardboard-hunter/internal/models"
+)
+
+// RunFunc performs one scheduled check
+type RunFunc func(ctx context.Context) error
+
+// Scheduler runs a check every interval plus a random jitter
+type Scheduler struct {
+	interval time.Duration
+	jitter   time.Duration
+	run      RunFunc
+
+	mu        sync.Mutex
+	running   bool
+	lastRun   time.Time
+	lastError string
+	nextRun   time.Time
+}
+
+// New creates a scheduler. It does nothing until Start is called.
+func New(interval, jitter time.Duration, run RunFunc) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		jitter:   jitter,
+		run:      run,
+	}
+}
+
+// Start runs the schedule loop in the background until ctx is cancelled.
+// lastRun is when the data was last refreshed, so a restart does not
+// trigger an immediate check when results are still fresh.
+func (s *Scheduler) Start(ctx context.Context, lastRun time.Time) {
+	s.mu.Lock()
+	s.lastRun = lastRun
+	s.nextRun = s.firstRun(lastRun, time.Now())
+	s.mu.Unlock()
+
+	go s.loop(ctx)
+}
+
+func (s *Scheduler) firstRun(lastRun, now time.Time) time.Time {
+	if lastRun.IsZero() || now.Sub(lastRun) >= s.interval {
+		// Stale data: refresh soon, but not all at the same instant as startup
+		return now.Add(s.randomJitter())
+	}
+	return lastRun.Add(s.interval).Add(s.randomJitter())
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	for {
+		s.mu.Lock()
+		wait := time.Until(s.nextRun)
+		s.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		s.running = true
+		s.mu.Unlock()
+
+		err := s.run(ctx)
+
+		s.mu.Lock()
+		s.running = false
+		s.lastRun = time.Now()
+		s.lastError = ""
+		if err != nil {
+			s.lastError = err.Error()
+		}
+		s.nextRun = s.lastRun.Add(s.interval).Add(s.randomJitter())
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.jitter)))
+}
+
+// Status returns the current scheduler state
+func (s *Scheduler) Status() models.ScheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := models.ScheduleStatus{
+		Enabled:   true,
+		Interval:  s.interval.String(),
+		Running:   s.running,
+		LastError: s.lastError,
+	}
+	if !s.lastRun.IsZero() {
+		lastRun := s.lastRun
+		status.LastRun = &lastRun
+	}
+	if !s.nextRun.IsZero() {
+		nextRun := s.nextRun
+		status.NextRun = &nextRun
+	}
+	return status
+}
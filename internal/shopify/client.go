@@ -1,11 +1,13 @@
 package shopify
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"cardboard-hunter/internal/models"
 	"cardboard-hunter/internal/utils"
@@ -22,26 +24,66 @@ type SearchResponse struct {
 
 // Product represents a Shopify product in search results
 type Product struct {
-	Title     string `json:"title"`
-	URL       string `json:"url"`
-	Price     string `json:"price"`
-	Available bool   `json:"available"`
+	Title          string `json:"title"`
+	URL            string `json:"url"`
+	Price          string `json:"price"`
+	CompareAtPrice string `json:"compare_at_price_max"` // "0.00" when not discounted
+	Available      bool   `json:"available"`
+}
+
+// ProductDetail is a product from /products/<handle>.js
+type ProductDetail struct {
+	Title    string    `json:"title"`
+	Handle   string    `json:"handle"`
+	Variants []Variant `json:"variants"`
+}
+
+// Variant is a single edition or language of a product. Prices are in cents.
+type Variant struct {
+	ID             int64  `json:"id"`
+	Title          string `json:"title"` // "Default Title" for products without options
+	Price          int64  `json:"price"`
+	CompareAtPrice *int64 `json:"compare_at_price"`
+	Available      bool   `json:"available"`
+}
+
+// Doer sends HTTP requests; *http.Client satisfies it
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // Client handles Shopify API requests
 type Client struct {
-	HTTPClient *http.Client
+	HTTPClient Doer
+	Prepare    func(*http.Request) // optional, applied to every request before it is sent
+}
+
+// newRequest builds a GET request and applies Prepare
+func (c *Client) newRequest(ctx context.Context, target string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Prepare != nil {
+		c.Prepare(req)
+	}
+	return req, nil
 }
 
 // Search performs a product search on a Shopify store
-func (c *Client) Search(baseURL, gameName string) ([]Product, error) {
+func (c *Client) Search(ctx context.Context, baseURL, gameName string) ([]Product, error) {
 	searchURL := fmt.Sprintf(
 		"%s/search/suggest.json?q=%s&resources[type]=product&resources[limit]=10",
 		baseURL,
 		url.QueryEscape(gameName),
 	)
 
-	resp, err := c.HTTPClient.Get(searchURL)
+	req, err := c.newRequest(ctx, searchURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -60,53 +102,77 @@ func (c *Client) Search(baseURL, gameName string) ([]Product, error) {
 	return data.Resources.Results.Products, nil
 }
 
-// FindMatches finds all matching products from search results (up to limit)
-func FindMatches(gameName string, products []Product, baseURL string, limit int) []models.ProductMatch {
-	var matches []models.ProductMatch
-	for _, product := range products {
-		if utils.ShouldExclude(product.Title) {
-			continue
-		}
-		if utils.FuzzyMatch(gameName, product.Title) {
-			matches = append(matches, models.ProductMatch{
-				Title:    product.Title,
-				URL:      baseURL + product.URL,
-				Price:    product.Price,
-				PriceNum: utils.ParsePrice(product.Price),
-				InStock:  product.Available,
-			})
-			if len(matches) >= limit {
-				break
-			}
-		}
+// Product fetches a product with all of its variants
+func (c *Client) Product(ctx context.Context, baseURL, handle string) (*ProductDetail, error) {
+	productURL := fmt.Sprintf("%s/products/%s.js", baseURL, url.PathEscape(handle))
+
+	req, err := c.newRequest(ctx, productURL)
+	if err != nil {
+		return nil, err
 	}
-	return matches
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", productURL, resp.Status)
+	}
+
+	var product ProductDetail
+	if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+		return nil, err
+	}
+	return &product, nil
 }
 
-// BuildStoreResult creates a StoreResult from matches
-// If an exact title match exists, returns only that match
-func BuildStoreResult(storeName string, matches []models.ProductMatch, gameName string) models.StoreResult {
-	if len(matches) == 0 {
-		return models.StoreResult{Store: storeName}
+// Handle returns the product handle from a search result URL
+// such as "/products/cascadia?_pos=1&_sid=abc"
+func Handle(productURL string) string {
+	path, _, _ := strings.Cut(productURL, "?")
+	_, handle, ok := strings.Cut(path, "/products/")
+	if !ok {
+		return ""
 	}
+	return strings.Trim(handle, "/")
+}
 
-	// Check for exact title match - if found, use only that
-	for _, m := range matches {
-		if utils.ExactTitleMatch(gameName, m.Title) {
-			matches = []models.ProductMatch{m}
-			break
+// VariantMatches returns one match per variant, titled "<product> - <variant>".
+// A product without options yields a single match under its own title.
+func VariantMatches(p *ProductDetail, baseURL string) []models.ProductMatch {
+	matches := make([]models.ProductMatch, 0, len(p.Variants))
+	for _, v := range p.Variants {
+		title := p.Title
+		if len(p.Variants) > 1 || v.Title != "Default Title" {
+			title += " - " + v.Title
 		}
+		price := fmt.Sprintf("%.2f", float64(v.Price)/100)
+		m := models.ProductMatch{
+			Title:    title,
+			URL:      fmt.Sprintf("%s/products/%s?variant=%d", baseURL, p.Handle, v.ID),
+			Price:    price,
+			PriceNum: utils.ParsePrice(price),
+			InStock:  v.Available,
+		}
+		if v.CompareAtPrice != nil {
+			m.SetRegularPrice(float64(*v.CompareAtPrice) / 100)
+		}
+		matches = append(matches, m)
 	}
+	return matches
+}
 
-	first := matches[0]
-	return models.StoreResult{
-		Store:    storeName,
-		Found:    true,
-		Title:    first.Title,
-		URL:      first.URL,
-		Price:    first.Price,
-		PriceNum: first.PriceNum,
-		InStock:  first.InStock,
-		Matches:  matches,
+// Match converts a search result to a ProductMatch
+func (p Product) Match(baseURL string) models.ProductMatch {
+	m := models.ProductMatch{
+		Title:    p.Title,
+		URL:      baseURL + p.URL,
+		Price:    p.Price,
+		PriceNum: utils.ParsePrice(p.Price),
+		InStock:  p.Available,
 	}
+	m.SetRegularPrice(utils.ParsePrice(p.CompareAtPrice))
+	return m
 }
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cardboard-hunter/internal/models"
+)
+
+const defaultHistoryFile = "history.jsonl"
+
+// History records price observations in an append-only JSON Lines file
+type History struct {
+	filepath string
+	mu       sync.RWMutex
+}
+
+// NewHistory creates a new History instance
+func NewHistory(filepath string) *History {
+	if filepath == "" {
+		filepath = defaultHistoryFile
+	}
+	return &History{
+		filepath: filepath,
+	}
+}
+
+// Record appends every product match found in results as an observation at time at
+func (h *History) Record(results []models.GameResult, at time.Time) error {
+	var points []models.PricePoint
+	for _, gr := range results {
+		for _, sr := range gr.Results {
+			// Cached results were already recorded when first fetched
+			if sr.Error != "" || !sr.Found || sr.CachedAt != nil {
+				continue
+			}
+			for _, m := range sr.Matches {
+				points = append(points, models.PricePoint{
+					Time:     at,
+					Game:     gr.Name,
+					Store:    sr.Store,
+					Title:    m.Title,
+					URL:      m.URL,
+					Price:    m.Price,
+					PriceNum: m.PriceNum,
+					InStock:  m.InStock,
+				})
+			}
+		}
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.OpenFile(h.filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, p := range points {
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// GameHistory returns all observations for a game (case-insensitive), oldest first
+func (h *History) GameHistory(game string) ([]models.PricePoint, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	points := []models.PricePoint{}
+
+	f, err := os.Open(h.filepath)
+	if os.IsNotExist(err) {
+		return points, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var p models.PricePoint
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			// Skip a partially written line rather than losing the whole history
+			continue
+		}
+		if strings.EqualFold(p.Game, game) {
+			points = append(points, p)
+		}
+	}
+	return points, scanner.Err()
+}
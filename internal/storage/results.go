@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/utils"
+)
+
+const defaultResultsFile = "results.json"
+
+// Results persists the most recent completed check
+type Results struct {
+	filepath string
+	mu       sync.RWMutex
+}
+
+// NewResults creates a new Results instance
+func NewResults(filepath string) *Results {
+	if filepath == "" {
+		filepath = defaultResultsFile
+	}
+	return &Results{
+		filepath: filepath,
+	}
+}
+
+// Load returns the last saved check, or nil if none has been saved yet
+func (r *Results) Load() (*models.CheckResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, err := os.ReadFile(r.filepath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp models.CheckResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Save replaces the saved check with resp
+func (r *Results) Save(resp models.CheckResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(r.filepath, data, 0644)
+}
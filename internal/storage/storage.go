@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/utils"
 )
 
 const defaultStorageFile = "games.json"
@@ -59,5 +60,5 @@ func (s *Storage) SaveGames(games []models.Game) error {
 		return err
 	}
 
-	return os.WriteFile(s.filepath, data, 0644)
+	return utils.WriteFileAtomic(s.filepath, data, 0644)
 }
@@ -0,0 +1,127 @@
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"cardboard-hunter/internal/models"
+)
+
+// StatusError is returned for a store response with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "store returned " + e.Status
+}
+
+// ParseError reports a response that is not in the format the checker expects
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return "unexpected response: " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ConfigError reports a store config that cannot be used
+type ConfigError struct {
+	Msg string
+}
+
+func (e *ConfigError) Error() string { return e.Msg }
+
+// StoreError is an error reported by the store itself, such as a GraphQL error
+type StoreError struct {
+	Msg string
+}
+
+func (e *StoreError) Error() string { return e.Msg }
+
+// maxStoreMessage is how much of a store's own error text is kept
+const maxStoreMessage = 200
+
+// storeError wraps text sent by a store. The text ends up in results.json
+// and the results table, so it is kept to one short line.
+func storeError(prefix, remote string) *StoreError {
+	msg := []rune(strings.Join(strings.Fields(remote), " "))
+	if len(msg) > maxStoreMessage {
+		msg = append(msg[:maxStoreMessage-1], '…')
+	}
+	return &StoreError{Msg: prefix + string(msg)}
+}
+
+// errorResult turns a failed check into a StoreResult with its ErrorKind set
+func errorResult(store string, err error) models.StoreResult {
+	return models.StoreResult{Store: store, Error: err.Error(), ErrorKind: ClassifyError(err)}
+}
+
+// ClassifyError tells why a store check failed
+func ClassifyError(err error) models.ErrorKind {
+	var statusErr *StatusError
+	var parseErr *ParseError
+	var configErr *ConfigError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return models.ErrorCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return models.ErrorTimeout
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode == http.StatusForbidden || statusErr.StatusCode == http.StatusTooManyRequests:
+			return models.ErrorBlocked
+		case statusErr.StatusCode >= 500:
+			return models.ErrorHTTP5xx
+		default:
+			return models.ErrorHTTP4xx
+		}
+	case errors.As(err, &parseErr), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return models.ErrorParse
+	case errors.As(err, &configErr):
+		return models.ErrorConfig
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return models.ErrorTimeout
+	case errors.As(err, &netErr):
+		return models.ErrorNetwork
+	default:
+		return models.ErrorStore
+	}
+}
+
+// isTransient reports whether a failed request may succeed when retried:
+// timeouts, dropped connections and 429/5xx answers other than 501
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests ||
+			(statusErr.StatusCode >= 500 && statusErr.StatusCode != http.StatusNotImplemented)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// configError returns a ConfigError with a formatted message
+func configError(format string, args ...any) error {
+	return &ConfigError{Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,173 @@
+package stores
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FixtureMode controls what FixtureTransport does with requests
+type FixtureMode string
+
+const (
+	// FixtureRecord performs real requests and saves every response
+	FixtureRecord FixtureMode = "record"
+	// FixtureReplay answers from saved responses only, never touching the network
+	FixtureReplay FixtureMode = "replay"
+)
+
+// Fixture is a saved HTTP exchange
+type Fixture struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"requestBody,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	ContentType string      `json:"contentType,omitempty"` // fixtures recorded before Header only kept this
+	Body        string      `json:"body"`
+}
+
+// unsavedHeaders are response headers left out of fixtures: the body is
+// saved decoded and often trimmed by hand, and cookies may hold a session
+var unsavedHeaders = []string{"Content-Length", "Content-Encoding", "Transfer-Encoding", "Set-Cookie"}
+
+// FixtureTransport records responses to, or replays them from, a directory.
+// Files are stored as <dir>/<host>/<hash>.json where the hash covers the
+// method, URL and request body.
+type FixtureTransport struct {
+	Dir  string
+	Mode FixtureMode
+	Next http.RoundTripper // used when recording; http.DefaultTransport if nil
+}
+
+// UseFixtures installs a FixtureTransport on the shared HTTPClient
+func UseFixtures(dir string, mode FixtureMode) {
+	HTTPClient.Transport = &FixtureTransport{Dir: dir, Mode: mode, Next: HTTPClient.Transport}
+}
+
+// ParseFixtureSpec parses "record:<dir>" or "replay:<dir>"
+func ParseFixtureSpec(spec string) (FixtureMode, string, error) {
+	mode, dir, ok := strings.Cut(spec, ":")
+	if !ok || dir == "" || (FixtureMode(mode) != FixtureRecord && FixtureMode(mode) != FixtureReplay) {
+		return "", "", fmt.Errorf("fixture spec %q must be record:<dir> or replay:<dir>", spec)
+	}
+	return FixtureMode(mode), dir, nil
+}
+
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	path := t.fixturePath(req, reqBody)
+
+	if t.Mode == FixtureReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("no fixture for %s %s (expected %s)", req.Method, req.URL, path)
+		}
+		var f Fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", path, err)
+		}
+		return f.response(req), nil
+	}
+
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	f := Fixture{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		RequestBody: string(reqBody),
+		Status:      resp.StatusCode,
+		Header:      savedHeader(resp.Header),
+		Body:        string(body),
+	}
+	if err := f.save(path); err != nil {
+		return nil, err
+	}
+	return f.response(req), nil
+}
+
+func (t *FixtureTransport) fixturePath(req *http.Request, body []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.String())
+	h.Write(body)
+	name := hex.EncodeToString(h.Sum(nil))[:16] + ".json"
+	return filepath.Join(t.Dir, req.URL.Host, name)
+}
+
+// savedHeader returns the response headers worth replaying, such as
+// Content-Type and Retry-After
+func savedHeader(h http.Header) http.Header {
+	saved := h.Clone()
+	for _, name := range unsavedHeaders {
+		saved.Del(name)
+	}
+	if len(saved) == 0 {
+		return nil
+	}
+	return saved
+}
+
+func (f *Fixture) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Keep HTML bodies readable in diffs
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func (f *Fixture) response(req *http.Request) *http.Response {
+	header := f.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	if f.ContentType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", f.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
@@ -1,6 +1,9 @@
 package stores
 
 import (
+	"context"
+	"fmt"
+
 	"cardboard-hunter/internal/config"
 	"cardboard-hunter/internal/models"
 )
@@ -9,33 +12,64 @@ import (
 type GenericStore struct {
 	cfg     *config.StoreConfig
 	checker checker
+	err     error // set when the config could not be turned into a checker
 }
 
 type checker interface {
-	Check(gameName string) models.StoreResult
+	Check(ctx context.Context, q Query) models.StoreResult
 }
 
 // NewGenericStore creates a store from configuration
 func NewGenericStore(cfg *config.StoreConfig) *GenericStore {
-	var c checker
+	s := &GenericStore{cfg: cfg}
 	switch cfg.Type {
 	case config.StoreTypeShopify:
-		c = NewShopifyChecker(cfg)
+		s.checker = NewShopifyChecker(cfg)
 	case config.StoreTypeHTMLScraper:
-		c = NewScraperChecker(cfg)
+		sc, err := NewScraperChecker(cfg)
+		if err != nil {
+			s.err = fmt.Errorf("invalid scraper config: %w", err)
+		} else {
+			s.checker = sc
+		}
+	case config.StoreTypeSelector:
+		sc, err := NewSelectorChecker(cfg)
+		if err != nil {
+			s.err = fmt.Errorf("invalid selector config: %w", err)
+		} else {
+			s.checker = sc
+		}
+	case config.StoreTypeStructured:
+		s.checker = NewStructuredChecker(cfg)
 	case config.StoreTypeJSONAPI:
-		c = NewJSONAPIChecker(cfg)
+		jc, err := NewJSONAPIChecker(cfg)
+		if err != nil {
+			s.err = fmt.Errorf("invalid jsonApi config: %w", err)
+		} else {
+			s.checker = jc
+		}
+	case config.StoreTypeWooCommerce:
+		s.checker = NewWooCommerceChecker(cfg)
+	case config.StoreTypeMagento:
+		s.checker = NewMagentoChecker(cfg)
 	}
-	return &GenericStore{cfg: cfg, checker: c}
+	return s
 }
 
 func (s *GenericStore) Name() string {
 	return s.cfg.Name
 }
 
-func (s *GenericStore) Check(gameName string) models.StoreResult {
+func (s *GenericStore) Config() *config.StoreConfig {
+	return s.cfg
+}
+
+func (s *GenericStore) Check(ctx context.Context, q Query) models.StoreResult {
+	if s.err != nil {
+		return errorResult(s.cfg.Name, &ConfigError{Msg: s.err.Error()})
+	}
 	if s.checker == nil {
-		return models.StoreResult{Store: s.cfg.Name, Error: "unknown store type"}
+		return errorResult(s.cfg.Name, configError("unknown store type %q", s.cfg.Type))
 	}
-	return s.checker.Check(gameName)
+	return s.checker.Check(ctx, q)
 }
@@ -1,134 +1,166 @@
 package stores
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/url"
 	"strings"
 
 	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/jsonpath"
 	"cardboard-hunter/internal/models"
 	"cardboard-hunter/internal/utils"
 )
 
 // JSONAPIChecker implements checking for JSON API stores
 type JSONAPIChecker struct {
-	cfg *config.StoreConfig
+	cfg      *config.StoreConfig
+	products jsonpath.Path
+	fields   jsonFieldPaths
+}
+
+// jsonFieldPaths holds the parsed JSONFieldMap; nil paths are unset fields
+type jsonFieldPaths struct {
+	title, price, regularPrice, url, quantity, stockStatus jsonpath.Path
 }
 
 // NewJSONAPIChecker creates a new JSON API checker from config
-func NewJSONAPIChecker(cfg *config.StoreConfig) *JSONAPIChecker {
-	return &JSONAPIChecker{cfg: cfg}
+func NewJSONAPIChecker(cfg *config.StoreConfig) (*JSONAPIChecker, error) {
+	c := &JSONAPIChecker{cfg: cfg}
+	if cfg.JSONAPI == nil {
+		return c, nil
+	}
+
+	var err error
+	if c.products, err = jsonpath.Parse(cfg.JSONAPI.ProductsPath); err != nil {
+		return nil, fmt.Errorf("productsPath: %w", err)
+	}
+
+	f := cfg.JSONAPI.Fields
+	for _, field := range []struct {
+		name string
+		expr string
+		dst  *jsonpath.Path
+	}{
+		{"title", f.Title, &c.fields.title},
+		{"price", f.Price, &c.fields.price},
+		{"regularPrice", f.RegularPrice, &c.fields.regularPrice},
+		{"url", f.URL, &c.fields.url},
+		{"quantity", f.Quantity, &c.fields.quantity},
+		{"stockStatus", f.StockStatus, &c.fields.stockStatus},
+	} {
+		if field.expr == "" {
+			continue
+		}
+		if *field.dst, err = jsonpath.Parse(field.expr); err != nil {
+			return nil, fmt.Errorf("fields.%s: %w", field.name, err)
+		}
+	}
+	return c, nil
 }
 
-func (c *JSONAPIChecker) Check(gameName string) models.StoreResult {
+func (c *JSONAPIChecker) Check(ctx context.Context, q Query) models.StoreResult {
 	if c.cfg.JSONAPI == nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: "no jsonApi config"}
+		return errorResult(c.cfg.Name, configError("no jsonApi config"))
+	}
+	rules, err := newMatcher(c.cfg, q)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
 	}
 
 	searchURL := c.cfg.BaseURL + strings.Replace(
-		c.cfg.JSONAPI.SearchPath, "{query}", url.QueryEscape(gameName), 1)
+		c.cfg.JSONAPI.SearchPath, "{query}", url.QueryEscape(q.Text), 1)
 
-	resp, err := HTTPClient.Get(searchURL)
+	req, err := newRequest(ctx, c.cfg, "GET", searchURL, nil)
 	if err != nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: err.Error()}
+		return errorResult(c.cfg.Name, err)
+	}
+
+	resp, err := doRequest(c.cfg, req)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: err.Error()}
+		return errorResult(c.cfg.Name, err)
 	}
 
-	var data map[string]any
+	var data any
 	if err := json.Unmarshal(body, &data); err != nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: err.Error()}
+		return errorResult(c.cfg.Name, &ParseError{Err: err})
 	}
 
-	products := c.extractProducts(data)
-	fields := c.cfg.JSONAPI.Fields
-
 	var matches []models.ProductMatch
-	for _, p := range products {
-		title := getString(p, fields.Title)
-		if utils.ShouldExclude(title) || !utils.FuzzyMatch(gameName, title) {
+	for _, p := range c.extractProducts(data) {
+		title := strings.TrimSpace(c.fields.title.String(p))
+		if !rules.Matches(title) {
 			continue
 		}
 
-		price := strings.TrimSpace(getString(p, fields.Price))
-		matches = append(matches, models.ProductMatch{
+		price := c.price(p, c.fields.price)
+		m := models.ProductMatch{
 			Title:    title,
-			URL:      getString(p, fields.URL),
+			URL:      c.fields.url.String(p),
 			Price:    price,
 			PriceNum: utils.ParsePrice(price),
 			InStock:  c.determineStock(p),
-		})
-
-		if len(matches) >= 5 {
-			break
 		}
+		if c.fields.regularPrice != nil {
+			m.SetRegularPrice(utils.ParsePrice(c.price(p, c.fields.regularPrice)))
+		}
+		matches = append(matches, m)
 	}
 
-	return buildResult(c.cfg.Name, matches, gameName)
+	return buildResult(c.cfg, matches, q.Text)
 }
 
-func (c *JSONAPIChecker) extractProducts(data map[string]any) []map[string]any {
-	path := c.cfg.JSONAPI.ProductsPath
-	val, ok := data[path]
-	if !ok {
-		return nil
-	}
-
-	arr, ok := val.([]any)
-	if !ok {
-		return nil
-	}
-
-	var products []map[string]any
-	for _, item := range arr {
-		if m, ok := item.(map[string]any); ok {
-			products = append(products, m)
+// extractProducts collects the product objects selected by productsPath.
+// The path may select an array of products, a single product, or (with
+// wildcards) several arrays whose products are concatenated.
+func (c *JSONAPIChecker) extractProducts(data any) []any {
+	var products []any
+	for _, val := range c.products.All(data) {
+		switch v := val.(type) {
+		case []any:
+			for _, item := range v {
+				if _, ok := item.(map[string]any); ok {
+					products = append(products, item)
+				}
+			}
+		case map[string]any:
+			products = append(products, v)
 		}
 	}
 	return products
 }
 
-func (c *JSONAPIChecker) determineStock(p map[string]any) bool {
-	fields := c.cfg.JSONAPI.Fields
-
-	if fields.Quantity != "" {
-		if qty := getNumber(p, fields.Quantity); qty > 0 {
-			return true
+// price reads a price field. Numeric prices are formatted with two decimals
+// so 49.9 displays as "49.90".
+func (c *JSONAPIChecker) price(p any, path jsonpath.Path) string {
+	if n, ok := path.Get(p); ok {
+		if f, ok := n.(float64); ok {
+			return fmt.Sprintf("%.2f", f)
 		}
 	}
+	return strings.TrimSpace(path.String(p))
+}
 
-	if fields.StockStatus != "" && c.cfg.JSONAPI.InStockValue != "" {
-		status := getString(p, fields.StockStatus)
-		if status == c.cfg.JSONAPI.InStockValue {
+func (c *JSONAPIChecker) determineStock(p any) bool {
+	if c.fields.quantity != nil {
+		if qty, ok := c.fields.quantity.Number(p); ok && qty > 0 {
 			return true
 		}
 	}
 
-	return fields.Quantity == "" && fields.StockStatus == ""
-}
-
-func getString(m map[string]any, key string) string {
-	if v, ok := m[key]; ok {
-		if s, ok := v.(string); ok {
-			return s
+	if c.fields.stockStatus != nil && c.cfg.JSONAPI.InStockValue != "" {
+		if c.fields.stockStatus.String(p) == c.cfg.JSONAPI.InStockValue {
+			return true
 		}
 	}
-	return ""
-}
 
-func getNumber(m map[string]any, key string) float64 {
-	if v, ok := m[key]; ok {
-		switch n := v.(type) {
-		case float64:
-			return n
-		case int:
-			return float64(n)
-		}
-	}
-	return 0
+	return c.fields.quantity == nil && c.fields.stockStatus == nil
 }
@@ -1,51 +1,70 @@
 package stores
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"cardboard-hunter/internal/config"
 	"cardboard-hunter/internal/models"
-	"cardboard-hunter/internal/utils"
 )
 
 type LaRevanche struct {
+	cfg     *config.StoreConfig
 	name    string
 	baseURL string
 }
 
-func NewLaRevanche() *LaRevanche {
+// NewLaRevanche creates the La Revanche store. cfg is optional and
+// only overrides the name, base URL and shared store settings.
+func NewLaRevanche(cfg *config.StoreConfig) *LaRevanche {
+	if cfg == nil {
+		cfg = &config.StoreConfig{
+			ID:       "larevanche",
+			Name:     "La Revanche",
+			Enabled:  true,
+			Type:     config.StoreTypeBuiltin,
+			BaseURL:  "https://boutique.larevanche.ca",
+			Language: "fr",
+			Headers:  map[string]string{"Accept-Language": "fr-CA,fr;q=0.9,en;q=0.8"},
+		}
+	}
 	return &LaRevanche{
-		name:    "La Revanche",
-		baseURL: "https://boutique.larevanche.ca",
+		cfg:     cfg,
+		name:    cfg.Name,
+		baseURL: cfg.BaseURL,
 	}
 }
 
 func (s *LaRevanche) Name() string { return s.name }
 
-func (s *LaRevanche) Check(gameName string) models.StoreResult {
-	searchURL := fmt.Sprintf("%s/search?q=%s", s.baseURL, url.QueryEscape(gameName))
+func (s *LaRevanche) Config() *config.StoreConfig { return s.cfg }
+
+func (s *LaRevanche) Check(ctx context.Context, q Query) models.StoreResult {
+	rules, err := newMatcher(s.cfg, q)
+	if err != nil {
+		return errorResult(s.name, err)
+	}
+	searchURL := fmt.Sprintf("%s/search?q=%s", s.baseURL, url.QueryEscape(q.Text))
 
-	req, err := http.NewRequest("GET", searchURL, nil)
+	req, err := newRequest(ctx, s.cfg, "GET", searchURL, nil)
 	if err != nil {
-		return models.StoreResult{Store: s.name, Error: err.Error()}
+		return errorResult(s.name, err)
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Accept-Language", "fr-CA,fr;q=0.9,en;q=0.8")
 
-	resp, err := HTTPClient.Do(req)
+	resp, err := doRequest(s.cfg, req)
 	if err != nil {
-		return models.StoreResult{Store: s.name, Error: err.Error()}
+		return errorResult(s.name, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return models.StoreResult{Store: s.name, Error: err.Error()}
+		return errorResult(s.name, err)
 	}
 
 	html := string(body)
@@ -73,13 +92,18 @@ func (s *LaRevanche) Check(gameName string) models.StoreResult {
 		urls[slug] = m[1]
 	}
 
+	itemIDs := make([]string, len(itemMatches))
+	for i, item := range itemMatches {
+		itemIDs[i] = item[1]
+	}
+
 	var matches []models.ProductMatch
 	for _, item := range itemMatches {
 		itemID := item[1]
 		title := item[2]
 		priceStr := item[3]
 
-		if utils.ShouldExclude(title) || !utils.FuzzyMatch(gameName, title) {
+		if !rules.Matches(title) {
 			continue
 		}
 
@@ -99,8 +123,8 @@ func (s *LaRevanche) Check(gameName string) models.StoreResult {
 			productURL = fmt.Sprintf("%s/search?q=%s", s.baseURL, url.QueryEscape(title))
 		}
 
-		// Check stock status by looking for "Hors stock" near product references
-		inStock := !strings.Contains(html, itemID) || !containsNear(html, itemID, "Hors stock", 500)
+		// Check stock status by looking for "Hors stock" in the product's card
+		inStock := !outOfStock(html, itemID, itemIDs)
 
 		matches = append(matches, models.ProductMatch{
 			Title:    title,
@@ -109,34 +133,9 @@ func (s *LaRevanche) Check(gameName string) models.StoreResult {
 			PriceNum: priceNum,
 			InStock:  inStock,
 		})
-		if len(matches) >= 5 {
-			break
-		}
-	}
-
-	if len(matches) == 0 {
-		return models.StoreResult{Store: s.name}
 	}
 
-	// Prioritize exact match
-	for _, m := range matches {
-		if utils.ExactTitleMatch(gameName, m.Title) {
-			matches = []models.ProductMatch{m}
-			break
-		}
-	}
-
-	first := matches[0]
-	return models.StoreResult{
-		Store:    s.name,
-		Found:    true,
-		Title:    first.Title,
-		URL:      first.URL,
-		Price:    first.Price,
-		PriceNum: first.PriceNum,
-		InStock:  first.InStock,
-		Matches:  matches,
-	}
+	return buildResult(s.cfg, matches, q.Text)
 }
 
 func slugify(s string) string {
@@ -147,18 +146,29 @@ func slugify(s string) string {
 	return strings.Trim(s, "-")
 }
 
-func containsNear(html, anchor, search string, distance int) bool {
-	idx := strings.Index(html, anchor)
-	if idx == -1 {
-		return false
-	}
-	start := idx - distance
-	if start < 0 {
-		start = 0
-	}
-	end := idx + len(anchor) + distance
-	if end > len(html) {
-		end = len(html)
+// outOfStock reports whether "Hors stock" follows a mention of itemID
+// before the next product is mentioned. The ID appears both in the gtag
+// script and in the product's card, so every mention is checked.
+func outOfStock(html, itemID string, itemIDs []string) bool {
+	const maxCard = 1500
+	for from := 0; ; {
+		idx := strings.Index(html[from:], itemID)
+		if idx == -1 {
+			return false
+		}
+		start := from + idx + len(itemID)
+		end := min(start+maxCard, len(html))
+		for _, other := range itemIDs {
+			if other == itemID {
+				continue
+			}
+			if j := strings.Index(html[start:end], other); j != -1 {
+				end = start + j
+			}
+		}
+		if strings.Contains(html[start:end], "Hors stock") {
+			return true
+		}
+		from = start
 	}
-	return strings.Contains(html[start:end], search)
 }
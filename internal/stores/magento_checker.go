@@ -0,0 +1,145 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+)
+
+// magentoSearchQuery asks for the fields every Magento 2.3+ store exposes
+const magentoSearchQuery = `query ($search: String!, $pageSize: Int!) {
+  products(search: $search, pageSize: $pageSize) {
+    items {
+      name
+      url_key
+      stock_status
+      price_range {
+        minimum_price {
+          regular_price { value currency }
+          final_price { value currency }
+        }
+      }
+    }
+  }
+}`
+
+// MagentoChecker implements checking for Magento 2 stores via GraphQL
+type MagentoChecker struct {
+	cfg *config.StoreConfig
+}
+
+type magentoRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type magentoResponse struct {
+	Data struct {
+		Products struct {
+			Items []magentoProduct `json:"items"`
+		} `json:"products"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type magentoProduct struct {
+	Name        string `json:"name"`
+	URLKey      string `json:"url_key"`
+	StockStatus string `json:"stock_status"` // IN_STOCK or OUT_OF_STOCK
+	PriceRange  struct {
+		MinimumPrice struct {
+			RegularPrice magentoMoney `json:"regular_price"`
+			FinalPrice   magentoMoney `json:"final_price"`
+		} `json:"minimum_price"`
+	} `json:"price_range"`
+}
+
+type magentoMoney struct {
+	Value    float64 `json:"value"`
+	Currency string  `json:"currency"`
+}
+
+// NewMagentoChecker creates a new Magento GraphQL checker from config
+func NewMagentoChecker(cfg *config.StoreConfig) *MagentoChecker {
+	return &MagentoChecker{cfg: cfg}
+}
+
+func (c *MagentoChecker) Check(ctx context.Context, q Query) models.StoreResult {
+	rules, err := newMatcher(c.cfg, q)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	mcfg := config.MagentoConfig{}
+	if c.cfg.Magento != nil {
+		mcfg = *c.cfg.Magento
+	}
+	endpoint := mcfg.GraphQLPath
+	if endpoint == "" {
+		endpoint = "/graphql"
+	}
+
+	payload, err := json.Marshal(magentoRequest{
+		Query:     magentoSearchQuery,
+		Variables: map[string]any{"search": q.Text, "pageSize": 20},
+	})
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	req, err := newRequest(ctx, c.cfg, "POST", c.cfg.BaseURL+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if mcfg.StoreCode != "" {
+		req.Header.Set("Store", mcfg.StoreCode)
+	}
+
+	resp, err := doRequest(c.cfg, req)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	var data magentoResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return errorResult(c.cfg.Name, &ParseError{Err: err})
+	}
+	if len(data.Errors) > 0 {
+		return errorResult(c.cfg.Name, storeError("GraphQL error: ", data.Errors[0].Message))
+	}
+
+	var matches []models.ProductMatch
+	for _, p := range data.Data.Products.Items {
+		title := strings.TrimSpace(p.Name)
+		if !rules.Matches(title) {
+			continue
+		}
+
+		prices := p.PriceRange.MinimumPrice
+		m := models.ProductMatch{
+			Title:    title,
+			URL:      c.cfg.BaseURL + mcfg.ProductPath + "/" + p.URLKey + mcfg.URLSuffix,
+			Price:    formatCurrency(prices.FinalPrice.Value, prices.FinalPrice.Currency),
+			PriceNum: prices.FinalPrice.Value,
+			InStock:  p.StockStatus == "IN_STOCK",
+		}
+		m.SetRegularPrice(prices.RegularPrice.Value)
+		matches = append(matches, m)
+	}
+
+	return buildResult(c.cfg, matches, q.Text)
+}
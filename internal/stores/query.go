@@ -0,0 +1,160 @@
+package stores
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/utils"
+)
+
+// Query is what a store is asked to find: the search text and the game's
+// rules deciding which titles count as matches
+type Query struct {
+	Text      string
+	Include   []string // patterns every matching title must contain
+	Exclude   []string // patterns ruling a title out, on top of the store's
+	Expansion bool     // the game is an expansion: expansion patterns don't rule titles out
+
+	// Other names for the game by language, searched at stores in that language
+	Aliases map[string][]string
+}
+
+// QueryFor returns the query searching for a wishlist game
+func QueryFor(game models.Game) Query {
+	return Query{
+		Text:      game.Name,
+		Include:   game.Include,
+		Exclude:   game.Exclude,
+		Expansion: game.Expansion,
+		Aliases:   game.Aliases,
+	}
+}
+
+// Texts returns the names to search a store in language lang under: the
+// query text, then the aliases in that language. A store without a
+// language is searched under every alias. Names that normalize the same
+// are searched once.
+func (q Query) Texts(lang string) []string {
+	texts := []string{q.Text}
+	seen := map[string]bool{utils.NormalizeTitle(q.Text): true}
+	for _, l := range q.aliasLanguages() {
+		if lang != "" && l != lang {
+			continue
+		}
+		for _, alias := range q.Aliases[l] {
+			key := utils.NormalizeTitle(alias)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			texts = append(texts, alias)
+		}
+	}
+	return texts
+}
+
+// aliasLanguages returns the languages with aliases in a stable order
+func (q Query) aliasLanguages() []string {
+	langs := make([]string, 0, len(q.Aliases))
+	for l := range q.Aliases {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// CacheKey identifies the query in the result cache: the same text with
+// different rules gives different results. The text and aliases are
+// normalized, so "Catan: Seafarers" and "Catan Seafarers" share an entry;
+// patterns are kept as written, as their punctuation matters.
+func (q Query) CacheKey() string {
+	key := utils.NormalizeTitle(q.Text)
+	for _, p := range q.Include {
+		key += "|+" + p
+	}
+	for _, p := range q.Exclude {
+		key += "|-" + p
+	}
+	if q.Expansion {
+		key += "|expansion"
+	}
+	for _, l := range q.aliasLanguages() {
+		for _, alias := range q.Aliases[l] {
+			key += "|" + l + "=" + utils.NormalizeTitle(alias)
+		}
+	}
+	return key
+}
+
+// Search checks a store for a query under every name from Texts, at the
+// same time, and merges the results. Requests are still paced by the
+// store's rate limiter.
+func Search(ctx context.Context, s Store, q Query) models.StoreResult {
+	texts := q.Texts(s.Config().Language)
+	if len(texts) == 1 {
+		return s.Check(ctx, q)
+	}
+
+	results := make([]models.StoreResult, len(texts))
+	var wg sync.WaitGroup
+	for i, text := range texts {
+		wg.Add(1)
+		go func(i int, text string) {
+			defer wg.Done()
+			sub := q
+			sub.Text = text
+			results[i] = s.Check(ctx, sub)
+		}(i, text)
+	}
+	wg.Wait()
+
+	return mergeResults(s.Config(), texts, results)
+}
+
+// matcher decides which product titles answer a query at a store
+type matcher struct {
+	text   string
+	filter *utils.TitleFilter
+}
+
+// newMatcher combines the global, store and game exclusion rules.
+// cfg may be nil for a built-in store without a config file.
+func newMatcher(cfg *config.StoreConfig, q Query) (*matcher, error) {
+	global, expansion := config.DefaultExcludePatterns, config.DefaultExpansionPatterns
+	var exclude []string
+	if cfg != nil {
+		if cfg.GlobalExcludePatterns != nil {
+			global = cfg.GlobalExcludePatterns
+		}
+		if cfg.GlobalExpansionPatterns != nil {
+			expansion = cfg.GlobalExpansionPatterns
+		}
+		exclude = cfg.AllExcludePatterns()
+	}
+
+	exclude = append(exclude, global...)
+	if !q.Expansion {
+		exclude = append(exclude, expansion...)
+	}
+	exclude = append(exclude, q.Exclude...)
+
+	filter, err := utils.NewTitleFilter(q.Include, exclude)
+	if err != nil {
+		return nil, configError("%v", err)
+	}
+	return &matcher{text: q.Text, filter: filter}, nil
+}
+
+// Excluded reports whether a title is ruled out by the exclusion rules
+func (m *matcher) Excluded(title string) bool {
+	return m.filter.Excluded(title)
+}
+
+// Matches reports whether a title answers the query: not excluded, and
+// containing every word of the search text
+func (m *matcher) Matches(title string) bool {
+	return !m.Excluded(title) && utils.FuzzyMatch(m.text, title)
+}
@@ -0,0 +1,197 @@
+package stores
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/ratelimit"
+)
+
+// DefaultUserAgent is sent when neither the store nor stores.json sets one;
+// some stores refuse Go's default client name
+const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+
+// newRequest builds a request to a store with its configured query
+// parameters, User-Agent, headers and cookies applied
+func newRequest(ctx context.Context, cfg *config.StoreConfig, method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	prepareRequest(cfg, req)
+	return req, nil
+}
+
+// prepareRequest applies a store's request settings to req. Headers are set
+// after the User-Agent so a "User-Agent" header wins over userAgent.
+func prepareRequest(cfg *config.StoreConfig, req *http.Request) {
+	if len(cfg.QueryParams) > 0 {
+		// Append rather than re-encode so the URL the checker built is kept as is
+		extra := make(url.Values, len(cfg.QueryParams))
+		for k, v := range cfg.QueryParams {
+			extra.Set(k, v)
+		}
+		if req.URL.RawQuery != "" {
+			req.URL.RawQuery += "&"
+		}
+		req.URL.RawQuery += extra.Encode()
+	}
+
+	ua := cfg.UserAgent
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	names := make([]string, 0, len(cfg.Cookies))
+	for name := range cfg.Cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		req.AddCookie(&http.Cookie{Name: name, Value: cfg.Cookies[name]})
+	}
+}
+
+const (
+	// defaultBackoff pauses a store that answered 429 or 503 without Retry-After
+	defaultBackoff = 5 * time.Second
+	// defaultRetryBackoff is the first delay between retries, doubled each time
+	defaultRetryBackoff = time.Second
+)
+
+// maxRetryWait is the longest Retry-After we wait out to retry a request,
+// and the longest a store is paused for. A var so tests can shorten it.
+var maxRetryWait = 30 * time.Second
+
+// doRequest sends a store request through the rate limiter attached to its
+// context, if any. Non-2xx answers are returned as a *StatusError.
+// Transient failures are retried up to cfg.Retries times with exponential
+// backoff; a 429 or 503 also pauses the store for its Retry-After delay,
+// up to maxRetryWait.
+func doRequest(cfg *config.StoreConfig, req *http.Request) (*http.Response, error) {
+	retries := 0
+	if cfg.Retries != nil {
+		retries = *cfg.Retries
+	}
+	backoff := config.ParseDuration(cfg.RetryBackoff, defaultRetryBackoff)
+	limiter := ratelimit.FromContext(req.Context())
+
+	for attempt := 0; ; attempt++ {
+		resp, err := send(limiter, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		wait := backoff << attempt
+		if resp != nil {
+			if d, ok := ratelimit.RetryAfter(resp.Header, time.Now()); ok {
+				wait = d
+				if limiter != nil {
+					// A Retry-After of hours would hold every later check
+					// of the store in Limiter.Wait
+					limiter.Pause(min(d, maxRetryWait))
+				}
+			} else if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+				if limiter != nil {
+					limiter.Pause(defaultBackoff)
+				}
+			}
+		}
+		if attempt >= retries || !isTransient(err) || wait > maxRetryWait || (req.Body != nil && req.GetBody == nil) {
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+
+		next := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		req = next
+	}
+}
+
+// send makes a single attempt. On a non-2xx status the body is closed and
+// the response is returned alongside the *StatusError for its headers.
+func send(limiter *ratelimit.Limiter, req *http.Request) (*http.Response, error) {
+	release := func() {}
+	if limiter != nil {
+		var err error
+		if release, err = limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		release()
+		return resp, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseOnClose frees a limiter slot once the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// storeClient sends requests through doRequest for the Shopify client
+type storeClient struct {
+	cfg *config.StoreConfig
+}
+
+func (c storeClient) Do(req *http.Request) (*http.Response, error) {
+	return doRequest(c.cfg, req)
+}
+
+// fetchPage downloads a page with the store's request settings
+func fetchPage(ctx context.Context, cfg *config.StoreConfig, pageURL string) (string, error) {
+	req, err := newRequest(ctx, cfg, "GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := doRequest(cfg, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
@@ -0,0 +1,121 @@
+package stores
+
+import (
+	"sort"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/utils"
+)
+
+// defaultMaxMatches applies when neither the store nor stores.json sets maxMatches
+const defaultMaxMatches = 5
+
+// buildResult scores every match against the search, keeps the best
+// cfg.MaxMatches and makes the most relevant one the headline result.
+// An exact title match is always the headline.
+func buildResult(cfg *config.StoreConfig, matches []models.ProductMatch, gameName string) models.StoreResult {
+	return finishResult(cfg, rankMatches(matches, gameName), []string{gameName})
+}
+
+// mergeResults combines the results of searching a store under several
+// names (see Search). A product found by more than one search is kept once,
+// with its best confidence. The store only reports an error when every
+// search failed.
+func mergeResults(cfg *config.StoreConfig, texts []string, results []models.StoreResult) models.StoreResult {
+	var matches []models.ProductMatch
+	seen := make(map[string]int) // URL -> index in matches
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			continue
+		}
+		for _, m := range r.Matches {
+			if i, ok := seen[m.URL]; ok {
+				if m.Confidence > matches[i].Confidence {
+					matches[i] = m
+				}
+				continue
+			}
+			seen[m.URL] = len(matches)
+			matches = append(matches, m)
+		}
+	}
+	if failed == len(results) {
+		return results[0]
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	return finishResult(cfg, matches, texts)
+}
+
+// finishResult turns ranked matches into a store result, keeping the best
+// cfg.MaxMatches. An exact match of any of the searches is moved first to
+// become the headline; the other matches stay as alternatives, for the
+// language preference and for picking another match in the UI.
+func finishResult(cfg *config.StoreConfig, matches []models.ProductMatch, searches []string) models.StoreResult {
+	if len(matches) == 0 {
+		return models.StoreResult{Store: cfg.Name}
+	}
+
+	if i := exactMatch(matches, searches); i > 0 {
+		reordered := make([]models.ProductMatch, 0, len(matches))
+		reordered = append(reordered, matches[i])
+		reordered = append(reordered, matches[:i]...)
+		matches = append(reordered, matches[i+1:]...)
+	}
+
+	limit := cfg.MaxMatches
+	if limit <= 0 {
+		limit = defaultMaxMatches
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	first := matches[0]
+	return models.StoreResult{
+		Store:           cfg.Name,
+		Found:           true,
+		Title:           first.Title,
+		URL:             first.URL,
+		Price:           first.Price,
+		PriceNum:        first.PriceNum,
+		RegularPrice:    first.RegularPrice,
+		DiscountPercent: first.DiscountPercent,
+		OnSale:          first.OnSale,
+		InStock:         first.InStock,
+		Confidence:      first.Confidence,
+		Matches:         matches,
+	}
+}
+
+// exactMatch returns the index of the first match whose title is exactly
+// one of the searches, or -1
+func exactMatch(matches []models.ProductMatch, searches []string) int {
+	for i, m := range matches {
+		for _, search := range searches {
+			if utils.ExactTitleMatch(search, m.Title) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// rankMatches sets each match's Confidence and sorts by it, best first.
+// Equal scores keep the store's own order.
+func rankMatches(matches []models.ProductMatch, gameName string) []models.ProductMatch {
+	ranked := make([]models.ProductMatch, len(matches))
+	copy(ranked, matches)
+	for i := range ranked {
+		ranked[i].Confidence = utils.ScoreMatch(gameName, ranked[i].Title)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Confidence > ranked[j].Confidence
+	})
+	return ranked
+}
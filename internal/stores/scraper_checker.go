@@ -1,9 +1,8 @@
 package stores
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
@@ -15,10 +14,11 @@ import (
 
 // ScraperChecker implements checking for HTML scraping stores
 type ScraperChecker struct {
-	cfg          *config.StoreConfig
-	cardSplitter *regexp.Regexp
-	titleRegexps []*regexp.Regexp
-	priceRegexps []priceRegexp
+	cfg                 *config.StoreConfig
+	cardSplitter        *regexp.Regexp
+	titleRegexps        []*regexp.Regexp
+	priceRegexps        []priceRegexp
+	regularPriceRegexps []priceRegexp
 }
 
 type priceRegexp struct {
@@ -26,128 +26,201 @@ type priceRegexp struct {
 	groups config.PriceCaptureMode
 }
 
+// CardTrace records how a single product card was parsed, for debugging configs
+type CardTrace struct {
+	Index          int
+	HTML           string
+	TitleMatch     []string // submatches of the first matching title pattern
+	TitlePattern   int      // index of that pattern, -1 if none matched
+	TitleSource    string   // which pattern or selector found the title
+	Title          string
+	URL            string
+	Price          string
+	PriceNum       float64
+	PricePattern   int    // index of the matching price pattern, -1 if none matched
+	PriceSource    string // which pattern or selector found the price
+	RegularPrice   float64
+	RegularPattern int // index of the matching regular price pattern, -1 if none matched
+	RegularSource  string
+	InStock        bool
+	StockReason    string
+	Excluded       bool
+	Matched        bool // passed exclusion and fuzzy matching
+}
+
 // NewScraperChecker creates a new HTML scraper checker from config
-func NewScraperChecker(cfg *config.StoreConfig) *ScraperChecker {
+func NewScraperChecker(cfg *config.StoreConfig) (*ScraperChecker, error) {
 	sc := &ScraperChecker{cfg: cfg}
 
 	if cfg.Scraper == nil {
-		return sc
+		return sc, nil
 	}
 
-	sc.cardSplitter = regexp.MustCompile(cfg.Scraper.CardSplitter)
+	var err error
+	if sc.cardSplitter, err = config.CompilePattern(cfg.Scraper.CardSplitter); err != nil {
+		return nil, fmt.Errorf("cardSplitter: %w", err)
+	}
 
-	for _, p := range cfg.Scraper.TitlePatterns {
-		sc.titleRegexps = append(sc.titleRegexps, regexp.MustCompile(p))
+	for i, p := range cfg.Scraper.TitlePatterns {
+		re, err := config.CompilePattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("titlePatterns[%d]: %w", i, err)
+		}
+		// Trace indexes the match by these groups, so they must exist
+		groups, tg := re.NumSubexp(), cfg.Scraper.TitleGroups
+		if tg.Title <= 0 || tg.URL <= 0 || tg.Title > groups || tg.URL > groups {
+			return nil, fmt.Errorf("titlePatterns[%d] has %d capture groups but titleGroups uses title=%d url=%d",
+				i, groups, tg.Title, tg.URL)
+		}
+		sc.titleRegexps = append(sc.titleRegexps, re)
 	}
 
-	for _, pp := range cfg.Scraper.PricePatterns {
-		sc.priceRegexps = append(sc.priceRegexps, priceRegexp{
-			re:     regexp.MustCompile(pp.Pattern),
+	if sc.priceRegexps, err = compilePricePatterns("pricePatterns", cfg.Scraper.PricePatterns); err != nil {
+		return nil, err
+	}
+	if sc.regularPriceRegexps, err = compilePricePatterns("regularPricePatterns", cfg.Scraper.RegularPricePatterns); err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+func compilePricePatterns(field string, patterns []config.PricePattern) ([]priceRegexp, error) {
+	var out []priceRegexp
+	for i, pp := range patterns {
+		re, err := config.CompilePattern(pp.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", field, i, err)
+		}
+		out = append(out, priceRegexp{
+			re:     re,
 			groups: pp.Groups,
 		})
 	}
-
-	return sc
+	return out, nil
 }
 
-func (c *ScraperChecker) Check(gameName string) models.StoreResult {
+func (c *ScraperChecker) Check(ctx context.Context, q Query) models.StoreResult {
 	if c.cfg.Scraper == nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: "no scraper config"}
+		return errorResult(c.cfg.Name, configError("no scraper config"))
 	}
 
-	searchURL := c.cfg.BaseURL + strings.Replace(
-		c.cfg.Scraper.SearchPath, "{query}", url.QueryEscape(gameName), 1)
-
-	req, err := http.NewRequest("GET", searchURL, nil)
+	html, err := c.Fetch(ctx, q.Text)
 	if err != nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: err.Error()}
+		return errorResult(c.cfg.Name, err)
 	}
 
-	for k, v := range c.cfg.Headers {
-		req.Header.Set(k, v)
+	cards, err := c.Trace(html, q)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
 	}
 
-	resp, err := HTTPClient.Do(req)
-	if err != nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: err.Error()}
+	var matches []models.ProductMatch
+	for _, card := range cards {
+		if !card.Matched {
+			continue
+		}
+		m := models.ProductMatch{
+			Title:    card.Title,
+			URL:      card.URL,
+			Price:    card.Price,
+			PriceNum: card.PriceNum,
+			InStock:  card.InStock,
+		}
+		m.SetRegularPrice(card.RegularPrice)
+		matches = append(matches, m)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	return buildResult(c.cfg, matches, q.Text)
+}
+
+// SearchURL returns the store search URL for a query
+func (c *ScraperChecker) SearchURL(gameName string) string {
+	return c.cfg.BaseURL + strings.Replace(
+		c.cfg.Scraper.SearchPath, "{query}", url.QueryEscape(gameName), 1)
+}
+
+// Fetch downloads the search results page for a query
+func (c *ScraperChecker) Fetch(ctx context.Context, gameName string) (string, error) {
+	return fetchPage(ctx, c.cfg, c.SearchURL(gameName))
+}
+
+// Trace splits a search results page into cards and records what every
+// pattern captured. Cards without a title match are included.
+func (c *ScraperChecker) Trace(html string, q Query) ([]CardTrace, error) {
+	rules, err := newMatcher(c.cfg, q)
 	if err != nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: err.Error()}
+		return nil, err
 	}
 
-	html := string(body)
 	parts := c.cardSplitter.Split(html, -1)
 
-	var matches []models.ProductMatch
+	var cards []CardTrace
 	for i, cardHTML := range parts {
 		if i == 0 {
 			continue
 		}
 
-		titleMatch := c.findTitleMatch(cardHTML)
-		if titleMatch == nil {
+		card := CardTrace{Index: i, HTML: cardHTML, TitlePattern: -1, PricePattern: -1, RegularPattern: -1}
+		card.TitleMatch, card.TitlePattern = c.findTitleMatch(cardHTML)
+		if card.TitleMatch == nil {
+			cards = append(cards, card)
 			continue
 		}
 
-		title := strings.TrimSpace(titleMatch[c.cfg.Scraper.TitleGroups.Title])
-		productURL := titleMatch[c.cfg.Scraper.TitleGroups.URL]
-
-		if utils.ShouldExclude(title) || !utils.FuzzyMatch(gameName, title) {
-			continue
+		card.TitleSource = fmt.Sprintf("titlePatterns[%d]", card.TitlePattern)
+		card.Title = strings.TrimSpace(card.TitleMatch[c.cfg.Scraper.TitleGroups.Title])
+		card.URL = card.TitleMatch[c.cfg.Scraper.TitleGroups.URL]
+		card.Price, card.PriceNum, card.PricePattern = c.extractPrice(cardHTML, c.priceRegexps)
+		if card.PricePattern >= 0 {
+			card.PriceSource = fmt.Sprintf("pricePatterns[%d]", card.PricePattern)
 		}
-
-		price, priceNum := c.extractPrice(cardHTML)
-		matches = append(matches, models.ProductMatch{
-			Title:    title,
-			URL:      productURL,
-			Price:    price,
-			PriceNum: priceNum,
-			InStock:  c.determineStock(cardHTML),
-		})
-
-		if len(matches) >= 5 {
-			break
+		_, card.RegularPrice, card.RegularPattern = c.extractPrice(cardHTML, c.regularPriceRegexps)
+		if card.RegularPattern >= 0 {
+			card.RegularSource = fmt.Sprintf("regularPricePatterns[%d]", card.RegularPattern)
 		}
-	}
+		card.InStock, card.StockReason = c.determineStock(cardHTML)
+		card.Excluded = rules.Excluded(card.Title)
+		card.Matched = rules.Matches(card.Title)
 
-	return buildResult(c.cfg.Name, matches, gameName)
+		cards = append(cards, card)
+	}
+	return cards, nil
 }
 
-func (c *ScraperChecker) findTitleMatch(cardHTML string) []string {
-	for _, re := range c.titleRegexps {
+func (c *ScraperChecker) findTitleMatch(cardHTML string) ([]string, int) {
+	for i, re := range c.titleRegexps {
 		if m := re.FindStringSubmatch(cardHTML); m != nil {
-			return m
+			return m, i
 		}
 	}
-	return nil
+	return nil, -1
 }
 
-func (c *ScraperChecker) determineStock(cardHTML string) bool {
+// determineStock decides availability and explains which rule decided it
+func (c *ScraperChecker) determineStock(cardHTML string) (bool, string) {
 	scfg := c.cfg.Scraper
 
 	for _, indicator := range scfg.OutOfStockIndicators {
 		if strings.Contains(cardHTML, indicator) {
-			return false
+			return false, fmt.Sprintf("out-of-stock indicator %q found", indicator)
 		}
 	}
 
 	if scfg.StockLogic == "in_stock_required" {
 		for _, indicator := range scfg.InStockIndicators {
 			if strings.Contains(cardHTML, indicator) {
-				return true
+				return true, fmt.Sprintf("in-stock indicator %q found", indicator)
 			}
 		}
-		return false
+		return false, "no in-stock indicator found"
 	}
 
-	return true
+	return true, "no out-of-stock indicator found"
 }
 
-func (c *ScraperChecker) extractPrice(cardHTML string) (string, float64) {
-	for _, pp := range c.priceRegexps {
+func (c *ScraperChecker) extractPrice(cardHTML string, patterns []priceRegexp) (string, float64, int) {
+	for i, pp := range patterns {
 		m := pp.re.FindStringSubmatch(cardHTML)
 		if m == nil {
 			continue
@@ -155,40 +228,15 @@ func (c *ScraperChecker) extractPrice(cardHTML string) (string, float64) {
 
 		if pp.groups.Amount > 0 && pp.groups.Amount < len(m) {
 			price := utils.ParsePrice(m[pp.groups.Amount])
-			return fmt.Sprintf("%s%.2f", c.cfg.Scraper.PricePrefix, price), price
+			return fmt.Sprintf("%s%.2f", c.cfg.Scraper.PricePrefix, price), price, i
 		}
 
 		if pp.groups.Dollars > 0 && pp.groups.Cents > 0 &&
 			pp.groups.Dollars < len(m) && pp.groups.Cents < len(m) {
 			priceStr := m[pp.groups.Dollars] + "." + m[pp.groups.Cents]
 			price := utils.ParsePrice(priceStr)
-			return c.cfg.Scraper.PricePrefix + priceStr, price
-		}
-	}
-	return "", 0
-}
-
-func buildResult(storeName string, matches []models.ProductMatch, gameName string) models.StoreResult {
-	if len(matches) == 0 {
-		return models.StoreResult{Store: storeName}
-	}
-
-	for _, m := range matches {
-		if utils.ExactTitleMatch(gameName, m.Title) {
-			matches = []models.ProductMatch{m}
-			break
+			return c.cfg.Scraper.PricePrefix + priceStr, price, i
 		}
 	}
-
-	first := matches[0]
-	return models.StoreResult{
-		Store:    storeName,
-		Found:    true,
-		Title:    first.Title,
-		URL:      first.URL,
-		Price:    first.Price,
-		PriceNum: first.PriceNum,
-		InStock:  first.InStock,
-		Matches:  matches,
-	}
+	return "", 0, -1
 }
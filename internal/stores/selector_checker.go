@@ -0,0 +1,271 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+)
+
+// SelectorChecker implements checking for HTML stores configured with CSS
+// selectors, parsing the page into a tree rather than matching raw markup
+type SelectorChecker struct {
+	cfg  *config.StoreConfig
+	base *url.URL
+
+	card, title, link, price, regularPrice, outOfStock, inStock cascadia.Matcher
+}
+
+// NewSelectorChecker creates a new CSS selector checker from config
+func NewSelectorChecker(cfg *config.StoreConfig) (*SelectorChecker, error) {
+	sc := &SelectorChecker{cfg: cfg}
+
+	base, err := url.Parse(cfg.BaseURL + "/")
+	if err != nil {
+		return nil, fmt.Errorf("baseURL: %w", err)
+	}
+	sc.base = base
+
+	if cfg.Selector == nil {
+		return sc, nil
+	}
+
+	s := cfg.Selector
+	for _, f := range []struct {
+		name string
+		sel  string
+		dst  *cascadia.Matcher
+	}{
+		{"card", s.Card, &sc.card},
+		{"title", s.Title, &sc.title},
+		{"link", s.Link, &sc.link},
+		{"price", s.Price, &sc.price},
+		{"regularPrice", s.RegularPrice, &sc.regularPrice},
+		{"outOfStock", s.OutOfStock, &sc.outOfStock},
+		{"inStock", s.InStock, &sc.inStock},
+	} {
+		if f.sel == "" {
+			continue
+		}
+		if *f.dst, err = config.CompileSelector(f.sel); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	if sc.card == nil || sc.title == nil {
+		return nil, fmt.Errorf("card and title selectors are required")
+	}
+	return sc, nil
+}
+
+func (c *SelectorChecker) Check(ctx context.Context, q Query) models.StoreResult {
+	if c.cfg.Selector == nil {
+		return errorResult(c.cfg.Name, configError("no selector config"))
+	}
+
+	page, err := c.Fetch(ctx, q.Text)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	cards, err := c.Trace(page, q)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	var matches []models.ProductMatch
+	for _, card := range cards {
+		if !card.Matched {
+			continue
+		}
+		m := models.ProductMatch{
+			Title:    card.Title,
+			URL:      card.URL,
+			Price:    card.Price,
+			PriceNum: card.PriceNum,
+			InStock:  card.InStock,
+		}
+		m.SetRegularPrice(card.RegularPrice)
+		matches = append(matches, m)
+	}
+
+	return buildResult(c.cfg, matches, q.Text)
+}
+
+// SearchURL returns the store search URL for a query
+func (c *SelectorChecker) SearchURL(gameName string) string {
+	return c.cfg.BaseURL + strings.Replace(
+		c.cfg.Selector.SearchPath, "{query}", url.QueryEscape(gameName), 1)
+}
+
+// Fetch downloads the search results page for a query
+func (c *SelectorChecker) Fetch(ctx context.Context, gameName string) (string, error) {
+	return fetchPage(ctx, c.cfg, c.SearchURL(gameName))
+}
+
+// Trace parses a search results page and records what every selector found
+// in each product card. Cards without a title are included.
+func (c *SelectorChecker) Trace(page string, q Query) ([]CardTrace, error) {
+	rules, err := newMatcher(c.cfg, q)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, &ParseError{Err: err}
+	}
+
+	s := c.cfg.Selector
+	var cards []CardTrace
+	for i, node := range cascadia.QueryAll(doc, c.card) {
+		card := CardTrace{Index: i + 1, HTML: renderNode(node), TitlePattern: -1, PricePattern: -1, RegularPattern: -1}
+
+		titleNode := cascadia.Query(node, c.title)
+		if titleNode == nil {
+			cards = append(cards, card)
+			continue
+		}
+		card.TitleSource = fmt.Sprintf("selector %q", s.Title)
+		card.Title = nodeText(titleNode)
+		card.URL = c.productURL(node, titleNode)
+
+		if c.price != nil {
+			if n := cascadia.Query(node, c.price); n != nil {
+				if price, ok := parsePriceText(nodeValue(n, s.PriceAttr)); ok {
+					card.Price = fmt.Sprintf("%s%.2f", s.PricePrefix, price)
+					card.PriceNum = price
+					card.PriceSource = fmt.Sprintf("selector %q", s.Price)
+				}
+			}
+		}
+		if c.regularPrice != nil {
+			if n := cascadia.Query(node, c.regularPrice); n != nil {
+				if price, ok := parsePriceText(nodeValue(n, s.RegularPriceAttr)); ok {
+					card.RegularPrice = price
+					card.RegularSource = fmt.Sprintf("selector %q", s.RegularPrice)
+				}
+			}
+		}
+
+		card.InStock, card.StockReason = c.determineStock(node)
+		card.Excluded = rules.Excluded(card.Title)
+		card.Matched = rules.Matches(card.Title)
+
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// productURL finds the card's link and resolves it against baseURL
+func (c *SelectorChecker) productURL(card, titleNode *html.Node) string {
+	var href string
+	switch {
+	case c.link != nil:
+		if n := cascadia.Query(card, c.link); n != nil {
+			href = attr(n, "href")
+		}
+	case attr(titleNode, "href") != "":
+		href = attr(titleNode, "href")
+	default:
+		if n := cascadia.Query(titleNode, anyLink); n != nil {
+			href = attr(n, "href")
+		}
+	}
+	if href == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return href
+	}
+	return c.base.ResolveReference(ref).String()
+}
+
+// determineStock decides availability and explains which selector decided it
+func (c *SelectorChecker) determineStock(card *html.Node) (bool, string) {
+	s := c.cfg.Selector
+
+	if c.outOfStock != nil && cascadia.Query(card, c.outOfStock) != nil {
+		return false, fmt.Sprintf("outOfStock selector %q matched", s.OutOfStock)
+	}
+	if c.inStock != nil {
+		if cascadia.Query(card, c.inStock) != nil {
+			return true, fmt.Sprintf("inStock selector %q matched", s.InStock)
+		}
+		return false, fmt.Sprintf("inStock selector %q did not match", s.InStock)
+	}
+	return true, "no out-of-stock selector matched"
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// nodeValue returns the named attribute, or the element text when name is empty
+func nodeValue(n *html.Node, name string) string {
+	if name != "" {
+		return attr(n, name)
+	}
+	return nodeText(n)
+}
+
+// nodeText returns the element's text with whitespace collapsed
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func renderNode(n *html.Node) string {
+	var b strings.Builder
+	html.Render(&b, n)
+	return b.String()
+}
+
+var anyLink = cascadia.MustCompile("a[href]")
+
+var priceNumber = regexp.MustCompile(`\d[\d\s\x{00a0}\x{202f}.,]*`)
+
+// parsePriceText reads a price in English or French notation:
+// "$1,299.99", "54,99 $", "1 299,99 $" or "39.99"
+func parsePriceText(s string) (float64, bool) {
+	num := strings.TrimRight(priceNumber.FindString(s), " \u00a0\u202f.,")
+	if num == "" {
+		return 0, false
+	}
+	num = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(num)
+
+	// The last separator is decimal when followed by one or two digits
+	if i := strings.LastIndexAny(num, ".,"); i >= 0 && len(num)-i-1 <= 2 {
+		num = strings.NewReplacer(".", "", ",", "").Replace(num[:i]) + "." + num[i+1:]
+	} else {
+		num = strings.NewReplacer(".", "", ",", "").Replace(num)
+	}
+
+	price, err := strconv.ParseFloat(num, 64)
+	return price, err == nil
+}
@@ -1,63 +1,78 @@
 package stores
 
 import (
-	"strings"
+	"context"
+	"net/http"
 
 	"cardboard-hunter/internal/config"
 	"cardboard-hunter/internal/models"
 	"cardboard-hunter/internal/shopify"
-	"cardboard-hunter/internal/utils"
 )
 
 // ShopifyChecker implements checking for Shopify-based stores
 type ShopifyChecker struct {
-	cfg *config.StoreConfig
+	cfg    *config.StoreConfig
+	client *shopify.Client
 }
 
 // NewShopifyChecker creates a new Shopify checker from config
 func NewShopifyChecker(cfg *config.StoreConfig) *ShopifyChecker {
-	return &ShopifyChecker{cfg: cfg}
+	return &ShopifyChecker{
+		cfg: cfg,
+		client: &shopify.Client{
+			HTTPClient: storeClient{cfg: cfg},
+			Prepare:    func(req *http.Request) { prepareRequest(cfg, req) },
+		},
+	}
 }
 
-func (c *ShopifyChecker) Check(gameName string) models.StoreResult {
-	products, err := ShopifyClient.Search(c.cfg.BaseURL, gameName)
+func (c *ShopifyChecker) Check(ctx context.Context, q Query) models.StoreResult {
+	rules, err := newMatcher(c.cfg, q)
 	if err != nil {
-		return models.StoreResult{Store: c.cfg.Name, Error: err.Error()}
+		return errorResult(c.cfg.Name, err)
 	}
-
-	var excludes []string
-	if c.cfg.Shopify != nil {
-		excludes = c.cfg.Shopify.ExcludePatterns
+	products, err := c.client.Search(ctx, c.cfg.BaseURL, q.Text)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
 	}
 
+	variants := c.cfg.Shopify != nil && c.cfg.Shopify.Variants
+
 	var matches []models.ProductMatch
 	for _, p := range products {
-		if shouldExcludeByPatterns(p.Title, excludes) || utils.ShouldExclude(p.Title) {
+		if !rules.Matches(p.Title) {
 			continue
 		}
-		if utils.FuzzyMatch(gameName, p.Title) {
-			matches = append(matches, models.ProductMatch{
-				Title:    p.Title,
-				URL:      c.cfg.BaseURL + p.URL,
-				Price:    p.Price,
-				PriceNum: utils.ParsePrice(p.Price),
-				InStock:  p.Available,
-			})
-			if len(matches) >= 5 {
-				break
+
+		if variants {
+			if found := c.variantMatches(ctx, p); found != nil {
+				// Variant titles add the edition ("Deluxe", "FR"), which
+				// the exclusion rules must see too
+				for _, m := range found {
+					if !rules.Excluded(m.Title) {
+						matches = append(matches, m)
+					}
+				}
+				continue
 			}
 		}
+		matches = append(matches, p.Match(c.cfg.BaseURL))
 	}
 
-	return shopify.BuildStoreResult(c.cfg.Name, matches, gameName)
+	return buildResult(c.cfg, matches, q.Text)
 }
 
-func shouldExcludeByPatterns(title string, patterns []string) bool {
-	t := strings.ToLower(title)
-	for _, p := range patterns {
-		if strings.Contains(t, strings.ToLower(p)) {
-			return true
-		}
+// variantMatches expands a search result into its variants, so editions
+// sold as options of one product get their own price and stock. It returns
+// nil when the lookup fails and the search result should be used as is.
+func (c *ShopifyChecker) variantMatches(ctx context.Context, p shopify.Product) []models.ProductMatch {
+	handle := shopify.Handle(p.URL)
+	if handle == "" {
+		return nil
+	}
+	detail, err := c.client.Product(ctx, c.cfg.BaseURL, handle)
+	if err != nil || len(detail.Variants) == 0 {
+		return nil
 	}
-	return false
+	return shopify.VariantMatches(detail, c.cfg.BaseURL)
 }
@@ -1,19 +1,20 @@
 package stores
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"time"
 
 	"cardboard-hunter/internal/config"
 	"cardboard-hunter/internal/models"
-	"cardboard-hunter/internal/shopify"
 )
 
 // Store represents a board game store with checking capabilities
 type Store interface {
 	Name() string
-	Check(gameName string) models.StoreResult
+	Config() *config.StoreConfig
+	Check(ctx context.Context, q Query) models.StoreResult
 }
 
 // HTTPClient is the shared HTTP client for all stores
@@ -21,11 +22,6 @@ var HTTPClient = &http.Client{
 	Timeout: 15 * time.Second,
 }
 
-// ShopifyClient is the shared Shopify API client
-var ShopifyClient = &shopify.Client{
-	HTTPClient: HTTPClient,
-}
-
 // GetAllStores returns all available store implementations
 func GetAllStores() []Store {
 	configDir := os.Getenv("CARDBOARD_CONFIG_DIR")
@@ -38,17 +34,28 @@ func GetAllStores() []Store {
 
 	var stores []Store
 	for _, ref := range mainCfg.Stores {
+		storeCfg, err := loader.LoadStoreConfig(ref)
+		if err != nil {
+			continue
+		}
+
 		if ref.Builtin {
-			if s := getBuiltinStore(ref.ID); s != nil {
+			if storeCfg != nil {
+				if !storeCfg.Enabled {
+					continue
+				}
+				applyDefaults(storeCfg, mainCfg.Defaults)
+			}
+			if s := getBuiltinStore(ref.ID, storeCfg); s != nil {
 				stores = append(stores, s)
 			}
 			continue
 		}
 
-		storeCfg, err := loader.LoadStoreConfig(ref)
-		if err != nil || storeCfg == nil || !storeCfg.Enabled {
+		if storeCfg == nil || !storeCfg.Enabled {
 			continue
 		}
+		applyDefaults(storeCfg, mainCfg.Defaults)
 		stores = append(stores, NewGenericStore(storeCfg))
 	}
 
@@ -58,17 +65,47 @@ func GetAllStores() []Store {
 	return stores
 }
 
-func getBuiltinStore(id string) Store {
+// applyDefaults fills store settings left empty with the stores.json defaults
+func applyDefaults(cfg *config.StoreConfig, defaults config.DefaultConfig) {
+	if cfg.CacheTTL == "" {
+		cfg.CacheTTL = defaults.CacheTTL
+	}
+	if cfg.MaxMatches == 0 {
+		cfg.MaxMatches = defaults.MaxMatches
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaults.UserAgent
+	}
+	if cfg.Retries == nil {
+		retries := defaults.Retries
+		cfg.Retries = &retries
+	}
+	if cfg.RetryBackoff == "" {
+		cfg.RetryBackoff = defaults.RetryBackoff
+	}
+	cfg.GlobalExcludePatterns = defaults.ExcludePatterns
+	cfg.GlobalExpansionPatterns = defaults.ExpansionPatterns
+}
+
+// getBuiltinStore returns a Go-implemented store. cfg may be nil, in which
+// case the store's built-in settings are used.
+func getBuiltinStore(id string, cfg *config.StoreConfig) Store {
 	switch id {
 	case "larevanche":
-		return NewLaRevanche()
+		return NewLaRevanche(cfg)
 	default:
 		return nil
 	}
 }
 
+// NewBuiltinStore returns the Go-implemented store with cfg's ID, or nil
+// when no builtin store has that ID
+func NewBuiltinStore(cfg *config.StoreConfig) Store {
+	return getBuiltinStore(cfg.ID, cfg)
+}
+
 func builtinStores() []Store {
 	return []Store{
-		NewLaRevanche(),
+		NewLaRevanche(nil),
 	}
 }
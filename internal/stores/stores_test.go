@@ -0,0 +1,617 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/ratelimit"
+)
+
+// To refresh fixtures from the live sites run, from the repository root:
+//
+//	CARDBOARD_FIXTURES=record:internal/stores/testdata/fixtures ./cardboard-hunter check Cascadia
+//
+// then trim the recorded bodies and update the expectations below.
+const fixturesDir = "testdata/fixtures"
+
+var storeCases = []struct {
+	store string              // store ID
+	cfg   *config.StoreConfig // for store types no default store uses
+	query string
+	game  models.Game // wishlist rules (include, exclude, expansion) applied to the query
+	want  []models.ProductMatch
+}{
+	{
+		store: "boardgamebliss",
+		query: "Cascadia",
+		// Exact title match heads "Cascadia Junior"; the expansion is excluded
+		want: []models.ProductMatch{
+			{Title: "Cascadia", Price: "39.99", PriceNum: 39.99, URL: "https://www.boardgamebliss.com/products/cascadia", InStock: true, Confidence: 1},
+			{Title: "Cascadia Junior", Price: "29.99", PriceNum: 29.99, URL: "https://www.boardgamebliss.com/products/cascadia-junior", InStock: false, Confidence: 0.73},
+		},
+	},
+	{
+		store: "games401",
+		query: "Cascadia",
+		// Sleeves (store excludePatterns) and the pre-order are filtered out
+		want: []models.ProductMatch{
+			{Title: "Cascadia Board Game", Price: "44.95", PriceNum: 44.95, URL: "https://store.401games.ca/products/cascadia-board-game", InStock: false, Confidence: 0.93},
+			{Title: "Cascadia: Rolling Hills", Price: "34.95", PriceNum: 34.95, URL: "https://store.401games.ca/products/cascadia-rolling-hills", InStock: true, Confidence: 0.59},
+		},
+	},
+	{
+		store: "greatboardgames",
+		query: "Cascadia",
+		want: []models.ProductMatch{
+			{Title: "Cascadia", Price: "$39.99", PriceNum: 39.99, URL: "https://www.greatboardgames.ca/games/cascadia", InStock: true, Confidence: 1},
+			{Title: "Cascadia Rolling Hills", Price: "$34.99", PriceNum: 34.99, URL: "https://www.greatboardgames.ca/games/cascadia-rolling-hills", InStock: false, Confidence: 0.59},
+		},
+	},
+	{
+		store: "lapioche",
+		query: "Cascadia",
+		want: []models.ProductMatch{
+			{Title: "Cascadia (Français)", Price: "44.99", PriceNum: 44.99, URL: "https://boutiquelapioche.com/products/cascadia-fr", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia (Anglais)", Price: "42.99", PriceNum: 42.99, URL: "https://boutiquelapioche.com/products/cascadia-en", InStock: false, Confidence: 0.96},
+		},
+	},
+	{
+		store: "boardgamesnmore",
+		query: "Cascadia",
+		want: []models.ProductMatch{
+			{Title: "Cascadia: Landmarks", Price: "$34.99", PriceNum: 34.99, URL: "https://www.boardgamesnmore.com/cascadia-landmarks", InStock: false, Confidence: 0.71},
+			{Title: "Cascadia Rolling Hills", Price: "$32.50", PriceNum: 32.5, URL: "https://www.boardgamesnmore.com/cascadia-rolling-hills", InStock: true, Confidence: 0.59},
+		},
+	},
+	{
+		store: "levalet",
+		query: "Cascadia",
+		// Both title attribute orders are handled; "Rupture" marks out of stock;
+		// Magento's oldPrice is the regular price of a discounted item; the
+		// expansion is excluded
+		want: []models.ProductMatch{
+			{Title: "Cascadia (FR)", Price: "$54.99", PriceNum: 54.99, RegularPrice: 59.99, DiscountPercent: 8, OnSale: true, URL: "https://levalet.com/fr/cascadia-fr", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia Rolling Hills", Price: "$29.99", PriceNum: 29.99, URL: "https://levalet.com/fr/cascadia-rolling-hills", InStock: false, Confidence: 0.59},
+		},
+	},
+	{
+		store: "levalet",
+		query: "Cascadia",
+		game:  models.Game{Expansion: true, Exclude: []string{"rolling hills"}},
+		// Hunting an expansion keeps "(Extension)"; the game's own exclude drops Rolling Hills
+		want: []models.ProductMatch{
+			{Title: "Cascadia (FR)", Price: "$54.99", PriceNum: 54.99, RegularPrice: 59.99, DiscountPercent: 8, OnSale: true, URL: "https://levalet.com/fr/cascadia-fr", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia - Paysages (Extension)", Price: "$39.99", PriceNum: 39.99, URL: "https://levalet.com/fr/cascadia-paysages", InStock: true, Confidence: 0.57},
+		},
+	},
+	{
+		store: "levalet",
+		query: "Cascadia",
+		game:  models.Game{Include: []string{`/\(fr\)$/`}},
+		// Include patterns may be regular expressions
+		want: []models.ProductMatch{
+			{Title: "Cascadia (FR)", Price: "$54.99", PriceNum: 54.99, RegularPrice: 59.99, DiscountPercent: 8, OnSale: true, URL: "https://levalet.com/fr/cascadia-fr", InStock: true, Confidence: 0.96},
+		},
+	},
+	{
+		store: "larevanche",
+		query: "Cascadia",
+		// "Hors stock" in a product's card marks only that product out of stock
+		want: []models.ProductMatch{
+			{Title: "Cascadia [Français]", Price: "$49.99", PriceNum: 49.99, URL: "https://boutique.larevanche.ca/fc/cascadia-francais.html", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia [Anglais]", Price: "$47.99", PriceNum: 47.99, URL: "https://boutique.larevanche.ca/fc/cascadia-anglais.html", InStock: false, Confidence: 0.96},
+		},
+	},
+	{
+		store: "shopify-variants",
+		cfg: &config.StoreConfig{
+			ID: "shopify-variants", Name: "Shopify Variants", Type: config.StoreTypeShopify,
+			BaseURL: "https://variants.example.com",
+			Shopify: &config.ShopifyConfig{Variants: true},
+		},
+		query: "Cascadia",
+		// Each edition gets its own stock and compare-at price; the variant lookup
+		// overrides the search's stock, and a product without a saved lookup
+		// falls back to it
+		want: []models.ProductMatch{
+			{Title: "Cascadia - Français", Price: "44.99", PriceNum: 44.99, RegularPrice: 49.99, DiscountPercent: 10, OnSale: true, URL: "https://variants.example.com/products/cascadia?variant=41001", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia - Anglais", Price: "42.99", PriceNum: 42.99, URL: "https://variants.example.com/products/cascadia?variant=41002", InStock: false, Confidence: 0.96},
+			{Title: "Cascadia Junior", Price: "32.00", PriceNum: 32, URL: "https://variants.example.com/products/cascadia-junior?variant=41003", InStock: false, Confidence: 0.73},
+			{Title: "Cascadia Rolling Hills", Price: "29.99", PriceNum: 29.99, URL: "https://variants.example.com/products/cascadia-rolling-hills?_pos=3&_sid=4f2a1&_ss=r", InStock: false, Confidence: 0.59},
+		},
+	},
+	{
+		store: "shopify-variants",
+		cfg: &config.StoreConfig{
+			ID: "shopify-variants", Name: "Shopify Variants", Type: config.StoreTypeShopify,
+			BaseURL: "https://variants.example.com",
+			Shopify: &config.ShopifyConfig{Variants: true},
+		},
+		query: "Cascadia",
+		game:  models.Game{Exclude: []string{"anglais"}},
+		// Exclusion rules also apply to variant titles
+		want: []models.ProductMatch{
+			{Title: "Cascadia - Français", Price: "44.99", PriceNum: 44.99, RegularPrice: 49.99, DiscountPercent: 10, OnSale: true, URL: "https://variants.example.com/products/cascadia?variant=41001", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia Junior", Price: "32.00", PriceNum: 32, URL: "https://variants.example.com/products/cascadia-junior?variant=41003", InStock: false, Confidence: 0.73},
+			{Title: "Cascadia Rolling Hills", Price: "29.99", PriceNum: 29.99, URL: "https://variants.example.com/products/cascadia-rolling-hills?_pos=3&_sid=4f2a1&_ss=r", InStock: false, Confidence: 0.59},
+		},
+	},
+	{
+		store: "json-nested",
+		cfg: &config.StoreConfig{
+			ID: "json-nested", Name: "Nested JSON", Type: config.StoreTypeJSONAPI,
+			BaseURL: "https://api.example.com",
+			JSONAPI: &config.JSONAPIConfig{
+				SearchPath:   "/v2/search?q={query}",
+				ProductsPath: "data.items",
+				Fields: config.JSONFieldMap{
+					Title:        "attributes.name",
+					Price:        "attributes.pricing[0].amount",
+					RegularPrice: "attributes.pricing[1].amount",
+					URL:          "links.self",
+					Quantity:     "attributes.inventory.available",
+				},
+			},
+		},
+		query: "Cascadia",
+		// Numeric prices are formatted; quantities are numeric strings
+		want: []models.ProductMatch{
+			{Title: "Cascadia Board Game", Price: "44.90", PriceNum: 44.9, RegularPrice: 49.99, DiscountPercent: 10, OnSale: true, URL: "https://api.example.com/p/cascadia", InStock: true, Confidence: 0.93},
+			{Title: "Cascadia Junior", Price: "32.00", PriceNum: 32, URL: "https://api.example.com/p/cascadia-junior", InStock: false, Confidence: 0.73},
+		},
+	},
+	{
+		store: "html-selector",
+		cfg: &config.StoreConfig{
+			ID: "html-selector", Name: "HTML Selector", Type: config.StoreTypeSelector,
+			BaseURL: "https://selector.example.com/fr",
+			Selector: &config.SelectorConfig{
+				SearchPath:   "/recherche?q={query}",
+				Card:         "li.product-item",
+				Title:        "a.product-item-link",
+				Price:        ".price-box > .price, .special-price .price",
+				RegularPrice: ".old-price .price",
+				PricePrefix:  "$",
+				OutOfStock:   ".stock.unavailable",
+				InStock:      "button.tocart",
+			},
+		},
+		query: "Cascadia",
+		// Attribute order doesn't matter, relative links resolve against
+		// baseURL and French prices ("1 032,00 $") are parsed
+		want: []models.ProductMatch{
+			{Title: "Cascadia (FR)", Price: "$54.99", PriceNum: 54.99, RegularPrice: 59.99, DiscountPercent: 8, OnSale: true, URL: "https://selector.example.com/fr/cascadia-fr", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia Junior", Price: "$1032.00", PriceNum: 1032, URL: "https://selector.example.com/fr/cascadia-junior", InStock: false, Confidence: 0.73},
+			{Title: "Cascadia Rolling Hills", Price: "$29.99", PriceNum: 29.99, URL: "https://selector.example.com/fr/cascadia-rolling-hills", InStock: false, Confidence: 0.59},
+		},
+	},
+	{
+		store: "structured-data",
+		cfg: &config.StoreConfig{
+			ID: "structured-data", Name: "Structured Data", Type: config.StoreTypeStructured,
+			BaseURL:    "https://structured.example.com",
+			Structured: &config.StructuredConfig{SearchPath: "/search?q={query}"},
+		},
+		query: "Cascadia",
+		// JSON-LD (ItemList, ListPrice, offer lists) and microdata are both
+		// read; the Junior is in both and kept once, pre-orders count as out of
+		// stock, and the bundle's extra words rank it last
+		want: []models.ProductMatch{
+			{Title: "Cascadia Junior", Price: "$32.00", PriceNum: 32, URL: "https://structured.example.com/products/cascadia-junior", InStock: false, Confidence: 0.73},
+			{Title: "Cascadia Rolling Hills", Price: "$34.50", PriceNum: 34.5, URL: "https://structured.example.com/products/cascadia-rolling-hills", InStock: false, Confidence: 0.59},
+			{Title: "Cascadia & Friends Bundle", Price: "$89.99", PriceNum: 89.99, RegularPrice: 99.99, DiscountPercent: 10, OnSale: true, URL: "https://structured.example.com/products/cascadia-bundle", InStock: true, Confidence: 0.5},
+		},
+	},
+	{
+		store: "magento",
+		cfg: &config.StoreConfig{
+			ID: "magento", Name: "Magento Shop", Type: config.StoreTypeMagento,
+			BaseURL: "https://magento.example.com",
+			Magento: &config.MagentoConfig{StoreCode: "fr", ProductPath: "/fr"},
+		},
+		query: "Cascadia",
+		// URLs are built from url_key under the French store view; a regular
+		// price above the final price marks a sale; the expansion is excluded
+		want: []models.ProductMatch{
+			{Title: "Cascadia (FR)", Price: "$54.99", PriceNum: 54.99, RegularPrice: 59.99, DiscountPercent: 8, OnSale: true, URL: "https://magento.example.com/fr/cascadia-fr", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia Rolling Hills", Price: "$29.99", PriceNum: 29.99, URL: "https://magento.example.com/fr/cascadia-rolling-hills", InStock: false, Confidence: 0.59},
+		},
+	},
+	{
+		store: "woocommerce",
+		cfg: &config.StoreConfig{
+			ID: "woocommerce", Name: "WooCommerce Shop", Type: config.StoreTypeWooCommerce,
+			BaseURL:         "https://shop.example.com",
+			ExcludePatterns: []string{"Sleeve"},
+		},
+		query: "Cascadia",
+		// Prices are in cents; in stock but not purchasable counts as out of stock
+		want: []models.ProductMatch{
+			{Title: "Cascadia (Anglais)", Price: "$49.99", PriceNum: 49.99, URL: "https://shop.example.com/produit/cascadia-anglais/", InStock: true, Confidence: 0.96},
+			{Title: "Cascadia Junior", Price: "$32.00", PriceNum: 32, URL: "https://shop.example.com/produit/cascadia-junior/", InStock: false, Confidence: 0.73},
+			{Title: "Cascadia – Rolling Hills", Price: "$34.50", PriceNum: 34.5, RegularPrice: 39.99, DiscountPercent: 14, OnSale: true, URL: "https://shop.example.com/produit/cascadia-rolling-hills/", InStock: false, Confidence: 0.59},
+		},
+	},
+}
+
+// replayStores returns every default store, keyed by ID, with the shared
+// HTTP client answering from fixtures only
+func replayStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	prev := HTTPClient.Transport
+	HTTPClient.Transport = &FixtureTransport{Dir: fixturesDir, Mode: FixtureReplay}
+	t.Cleanup(func() { HTTPClient.Transport = prev })
+
+	loader := config.NewLoader("")
+	mainCfg, err := loader.LoadStoresConfig()
+	if err != nil {
+		t.Fatalf("loading stores.json: %v", err)
+	}
+
+	all := make(map[string]Store)
+	for _, ref := range mainCfg.Stores {
+		cfg, err := loader.LoadStoreConfig(ref)
+		if err != nil {
+			t.Fatalf("loading %s: %v", ref.File, err)
+		}
+		if ref.Builtin {
+			all[ref.ID] = getBuiltinStore(ref.ID, cfg)
+			continue
+		}
+		all[ref.ID] = NewGenericStore(cfg)
+	}
+	return all
+}
+
+func TestStoresAgainstFixtures(t *testing.T) {
+	all := replayStores(t)
+
+	tested := make(map[string]bool)
+	for _, tc := range storeCases {
+		tested[tc.store] = true
+		t.Run(tc.store, func(t *testing.T) {
+			s, ok := all[tc.store]
+			if tc.cfg != nil {
+				s, ok = NewGenericStore(tc.cfg), true
+			}
+			if !ok {
+				t.Fatalf("store %q is not configured", tc.store)
+			}
+
+			q := QueryFor(tc.game)
+			q.Text = tc.query
+			got := s.Check(context.Background(), q)
+			if got.Error != "" {
+				t.Fatalf("Check(%q) error: %s", tc.query, got.Error)
+			}
+			if !reflect.DeepEqual(got.Matches, tc.want) {
+				t.Errorf("Check(%q) matches:\n got  %+v\n want %+v", tc.query, got.Matches, tc.want)
+			}
+
+			// The headline result is always the first match
+			if len(tc.want) > 0 {
+				first := tc.want[0]
+				if !got.Found || got.Title != first.Title || got.PriceNum != first.PriceNum || got.InStock != first.InStock {
+					t.Errorf("headline = %q %.2f inStock=%t, want %q %.2f inStock=%t",
+						got.Title, got.PriceNum, got.InStock, first.Title, first.PriceNum, first.InStock)
+				}
+			}
+		})
+	}
+
+	for id := range all {
+		if !tested[id] {
+			t.Errorf("store %q has no fixture test case", id)
+		}
+	}
+}
+
+func TestReplayWithoutFixtureFails(t *testing.T) {
+	all := replayStores(t)
+
+	got := all["boardgamebliss"].Check(context.Background(), Query{Text: "No Such Game"})
+	if got.Error == "" {
+		t.Fatal("expected an error for a query without a recorded fixture")
+	}
+}
+
+func TestNewScraperCheckerRejectsMissingTitleGroups(t *testing.T) {
+	cfg := &config.StoreConfig{
+		ID:   "scraper",
+		Type: config.StoreTypeHTMLScraper,
+		Scraper: &config.ScraperConfig{
+			CardSplitter:  `<li class="product">`,
+			TitlePatterns: []string{`<a href="([^"]+)">([^<]+)</a>`},
+			TitleGroups:   config.CaptureGroups{Title: 3, URL: 1},
+		},
+	}
+	if _, err := NewScraperChecker(cfg); err == nil {
+		t.Fatal("expected an error for titleGroups.title beyond the pattern's capture groups")
+	}
+
+	cfg.Scraper.TitleGroups.Title = 2
+	if _, err := NewScraperChecker(cfg); err != nil {
+		t.Fatalf("valid titleGroups: %v", err)
+	}
+}
+
+func TestFixturesReplayResponseHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": "slow down"}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	for _, mode := range []FixtureMode{FixtureRecord, FixtureReplay} {
+		client := &http.Client{Transport: &FixtureTransport{Dir: dir, Mode: mode}}
+		resp, err := client.Get(srv.URL + "/search?q=Cascadia")
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("%s: status %d, want 429", mode, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Retry-After"); got != "120" {
+			t.Errorf("%s: Retry-After = %q, want \"120\"", mode, got)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: Content-Type = %q, want application/json", mode, got)
+		}
+		if got := resp.Header.Get("Set-Cookie"); got != "" {
+			t.Errorf("%s: Set-Cookie = %q, want it left out of the fixture", mode, got)
+		}
+	}
+}
+
+func TestPrepareRequest(t *testing.T) {
+	cfg := &config.StoreConfig{
+		UserAgent:   "hunter-test",
+		Headers:     map[string]string{"Accept-Language": "fr-CA"},
+		Cookies:     map[string]string{"currency": "CAD", "store": "fr"},
+		QueryParams: map[string]string{"lang": "fr"},
+	}
+	req, err := newRequest(context.Background(), cfg, "GET", "https://example.com/search?q=Ark+Nova", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := req.URL.String(), "https://example.com/search?q=Ark+Nova&lang=fr"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+	if got := req.Header.Get("User-Agent"); got != "hunter-test" {
+		t.Errorf("User-Agent = %q, want %q", got, "hunter-test")
+	}
+	if got := req.Header.Get("Accept-Language"); got != "fr-CA" {
+		t.Errorf("Accept-Language = %q, want %q", got, "fr-CA")
+	}
+	if got, want := req.Header.Get("Cookie"), "currency=CAD; store=fr"; got != want {
+		t.Errorf("Cookie = %q, want %q", got, want)
+	}
+
+	// A User-Agent header overrides userAgent; without either the default is sent
+	cfg.Headers["User-Agent"] = "from-headers"
+	req, _ = newRequest(context.Background(), cfg, "GET", "https://example.com/", nil)
+	if got := req.Header.Get("User-Agent"); got != "from-headers" {
+		t.Errorf("User-Agent = %q, want the header value", got)
+	}
+	req, _ = newRequest(context.Background(), &config.StoreConfig{}, "GET", "https://example.com/", nil)
+	if got := req.Header.Get("User-Agent"); got != DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want DefaultUserAgent", got)
+	}
+}
+
+func TestDoRequestRetriesAfterTooManyRequests(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	retries := 1
+	cfg := &config.StoreConfig{Retries: &retries}
+	ctx := ratelimit.WithLimiter(context.Background(), ratelimit.New(0, 1, 0))
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := doRequest(cfg, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || hits != 2 {
+		t.Errorf("status %d after %d requests, want 200 after 2", resp.StatusCode, hits)
+	}
+}
+
+func TestDoRequestCapsRetryAfterPause(t *testing.T) {
+	defer func(d time.Duration) { maxRetryWait = d }(maxRetryWait)
+	maxRetryWait = 50 * time.Millisecond
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	limiter := ratelimit.New(0, 0, 0)
+	ctx := ratelimit.WithLimiter(context.Background(), limiter)
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doRequest(&config.StoreConfig{}, req); err == nil {
+		t.Fatal("expected an error for a 429 answer")
+	}
+
+	// The store is paused for maxRetryWait, not for a day
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	release, err := limiter.Wait(waitCtx)
+	if err != nil {
+		t.Fatalf("limiter still paused after maxRetryWait: %v", err)
+	}
+	release()
+}
+
+func TestDoRequestClassifiesErrors(t *testing.T) {
+	status := http.StatusForbidden
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	retries := 2
+	cfg := &config.StoreConfig{Retries: &retries, RetryBackoff: "1ms"}
+	for _, tc := range []struct {
+		status int
+		kind   models.ErrorKind
+		hits   int
+	}{
+		{http.StatusForbidden, models.ErrorBlocked, 1},
+		{http.StatusNotFound, models.ErrorHTTP4xx, 1},
+		{http.StatusBadGateway, models.ErrorHTTP5xx, 3}, // transient: retried twice
+	} {
+		status, hits = tc.status, 0
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		_, err := doRequest(cfg, req)
+		if err == nil {
+			t.Fatalf("status %d: expected an error", tc.status)
+		}
+		if kind := ClassifyError(err); kind != tc.kind || hits != tc.hits {
+			t.Errorf("status %d: kind %q after %d requests, want %q after %d", tc.status, kind, hits, tc.kind, tc.hits)
+		}
+	}
+
+	if kind := ClassifyError(&ParseError{Err: errors.New("bad JSON")}); kind != models.ErrorParse {
+		t.Errorf("ParseError kind = %q, want %q", kind, models.ErrorParse)
+	}
+	if kind := ClassifyError(context.Canceled); kind != models.ErrorCancelled {
+		t.Errorf("context.Canceled kind = %q, want %q", kind, models.ErrorCancelled)
+	}
+}
+
+// stubStore answers each search text with canned titles, or an error
+type stubStore struct {
+	cfg    *config.StoreConfig
+	titles map[string][]string
+	mu     sync.Mutex
+	texts  []string // searched texts, in any order
+}
+
+func (s *stubStore) Name() string                { return s.cfg.Name }
+func (s *stubStore) Config() *config.StoreConfig { return s.cfg }
+
+func (s *stubStore) Check(ctx context.Context, q Query) models.StoreResult {
+	s.mu.Lock()
+	s.texts = append(s.texts, q.Text)
+	s.mu.Unlock()
+
+	titles, ok := s.titles[q.Text]
+	if !ok {
+		return errorResult(s.cfg.Name, &StatusError{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"})
+	}
+	var matches []models.ProductMatch
+	for _, title := range titles {
+		matches = append(matches, models.ProductMatch{Title: title, URL: "/" + title})
+	}
+	return buildResult(s.cfg, matches, q.Text)
+}
+
+func TestStoreErrorShortensRemoteText(t *testing.T) {
+	remote := "Internal error:\n\t" + strings.Repeat("très long ", 50)
+	err := storeError("GraphQL error: ", remote)
+
+	if strings.ContainsAny(err.Msg, "\n\t") {
+		t.Errorf("message %q should be a single line", err.Msg)
+	}
+	if got := utf8.RuneCountInString(err.Msg); got != len("GraphQL error: ")+maxStoreMessage {
+		t.Errorf("message has %d characters, want %d", got, len("GraphQL error: ")+maxStoreMessage)
+	}
+	if !strings.HasPrefix(err.Msg, "GraphQL error: Internal error: très") || !strings.HasSuffix(err.Msg, "…") {
+		t.Errorf("message = %q", err.Msg)
+	}
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	a := Query{Text: "Catan: Seafarers"}.CacheKey()
+	if b := (Query{Text: "catan  seafarers"}).CacheKey(); a != b {
+		t.Errorf("CacheKey %q != %q for the same normalized text", a, b)
+	}
+	include := Query{Text: "Catan", Include: []string{"promo"}}.CacheKey()
+	exclude := Query{Text: "Catan", Exclude: []string{"promo"}}.CacheKey()
+	if include == exclude {
+		t.Errorf("include and exclude rules share the cache key %q", include)
+	}
+}
+
+func TestSearchMergesAliases(t *testing.T) {
+	q := Query{
+		Text: "Ticket to Ride",
+		Aliases: map[string][]string{
+			"fr": {"Les Aventuriers du Rail", "Aventuriers du rail"}, // same once normalized
+			"de": {"Zug um Zug"},
+		},
+	}
+
+	s := &stubStore{
+		cfg: &config.StoreConfig{Name: "Stub", Language: "fr"},
+		titles: map[string][]string{
+			"Ticket to Ride":          {"Ticket to Ride: Europe", "Ticket to Ride: Rails & Sails"},
+			"Les Aventuriers du Rail": {"Les Aventuriers du Rail: Europe", "Ticket to Ride: Europe"},
+		},
+	}
+	got := Search(context.Background(), s, q)
+	sort.Strings(s.texts)
+	if want := []string{"Les Aventuriers du Rail", "Ticket to Ride"}; !reflect.DeepEqual(s.texts, want) {
+		t.Errorf("searched %q, want %q", s.texts, want)
+	}
+	var titles []string
+	for _, m := range got.Matches {
+		titles = append(titles, m.Title)
+	}
+	// The product found by both searches is listed once; ties keep search order
+	if want := []string{"Ticket to Ride: Europe", "Les Aventuriers du Rail: Europe", "Ticket to Ride: Rails & Sails"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("merged matches %q, want %q", titles, want)
+	}
+
+	// A failed alias search doesn't hide the other results
+	delete(s.titles, "Les Aventuriers du Rail")
+	if got := Search(context.Background(), s, q); got.Error != "" || len(got.Matches) != 2 {
+		t.Errorf("with a failed alias search: error %q, %d matches, want 2 matches", got.Error, len(got.Matches))
+	}
+
+	// Without a language the store is searched under every alias
+	s.cfg.Language, s.texts = "", nil
+	Search(context.Background(), s, q)
+	if len(s.texts) != 3 {
+		t.Errorf("store without a language searched %q, want 3 texts", s.texts)
+	}
+}
@@ -0,0 +1,404 @@
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdhtml "html"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+)
+
+// StructuredChecker implements checking for stores whose search page embeds
+// schema.org Product data, as JSON-LD scripts or microdata attributes
+type StructuredChecker struct {
+	cfg  *config.StoreConfig
+	base *url.URL
+}
+
+// NewStructuredChecker creates a new structured data checker from config
+func NewStructuredChecker(cfg *config.StoreConfig) *StructuredChecker {
+	base, _ := url.Parse(cfg.BaseURL + "/")
+	return &StructuredChecker{cfg: cfg, base: base}
+}
+
+func (c *StructuredChecker) Check(ctx context.Context, q Query) models.StoreResult {
+	if c.cfg.Structured == nil {
+		return errorResult(c.cfg.Name, configError("no structuredData config"))
+	}
+
+	page, err := c.Fetch(ctx, q.Text)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	cards, err := c.Trace(page, q)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	var matches []models.ProductMatch
+	for _, card := range cards {
+		if !card.Matched {
+			continue
+		}
+		m := models.ProductMatch{
+			Title:    card.Title,
+			URL:      card.URL,
+			Price:    card.Price,
+			PriceNum: card.PriceNum,
+			InStock:  card.InStock,
+		}
+		m.SetRegularPrice(card.RegularPrice)
+		matches = append(matches, m)
+	}
+
+	return buildResult(c.cfg, matches, q.Text)
+}
+
+// SearchURL returns the store search URL for a query
+func (c *StructuredChecker) SearchURL(gameName string) string {
+	return c.cfg.BaseURL + strings.Replace(
+		c.cfg.Structured.SearchPath, "{query}", url.QueryEscape(gameName), 1)
+}
+
+// Fetch downloads the search results page for a query
+func (c *StructuredChecker) Fetch(ctx context.Context, gameName string) (string, error) {
+	return fetchPage(ctx, c.cfg, c.SearchURL(gameName))
+}
+
+// Trace extracts every schema.org Product from a page, JSON-LD first, then
+// microdata. A product present in both forms is reported once.
+func (c *StructuredChecker) Trace(page string, q Query) ([]CardTrace, error) {
+	rules, err := newMatcher(c.cfg, q)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, &ParseError{Err: err}
+	}
+
+	var products []map[string]any
+	var sources []string
+	for _, script := range findAll(doc, isJSONLDScript) {
+		var data any
+		if err := json.Unmarshal([]byte(nodeRawText(script)), &data); err != nil {
+			continue
+		}
+		for _, p := range collectProducts(data) {
+			products = append(products, p)
+			sources = append(sources, "JSON-LD")
+		}
+	}
+	for _, item := range findAll(doc, isMicrodataProduct) {
+		products = append(products, microdataItem(item))
+		sources = append(sources, "microdata")
+	}
+
+	seen := make(map[string]bool)
+	var cards []CardTrace
+	for i, p := range products {
+		card := c.productCard(p, sources[i])
+		key := strings.ToLower(card.Title) + "|" + card.URL
+		if card.Title == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		card.Index = len(cards) + 1
+		card.Excluded = rules.Excluded(card.Title)
+		card.Matched = rules.Matches(card.Title)
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// productCard reads name, url and the first offer of a schema.org Product
+func (c *StructuredChecker) productCard(p map[string]any, source string) CardTrace {
+	raw, _ := json.Marshal(p)
+	card := CardTrace{
+		HTML:           string(raw),
+		TitlePattern:   -1,
+		PricePattern:   -1,
+		RegularPattern: -1,
+		TitleSource:    source + " Product.name",
+		Title:          strings.TrimSpace(stdhtml.UnescapeString(schemaString(p["name"]))),
+	}
+
+	offer := firstOffer(p["offers"])
+	link := schemaString(p["url"])
+	if link == "" {
+		link = schemaString(offer["url"])
+	}
+	if link != "" {
+		if ref, err := url.Parse(strings.TrimSpace(link)); err == nil {
+			link = c.base.ResolveReference(ref).String()
+		}
+	}
+	card.URL = link
+
+	priceField := "price"
+	if _, ok := offer["price"]; !ok {
+		priceField = "lowPrice" // AggregateOffer
+	}
+	if price, ok := parsePriceText(schemaString(offer[priceField])); ok {
+		card.PriceNum = price
+		card.Price = formatCurrency(price, schemaString(offer["priceCurrency"]))
+		card.PriceSource = source + " Offer." + priceField
+	}
+
+	for _, spec := range schemaList(offer["priceSpecification"]) {
+		priceType := schemaString(spec["priceType"])
+		if strings.HasSuffix(priceType, "ListPrice") || strings.HasSuffix(priceType, "StrikethroughPrice") {
+			if regular, ok := parsePriceText(schemaString(spec["price"])); ok {
+				card.RegularPrice = regular
+				card.RegularSource = source + " priceSpecification " + schemaTerm(priceType)
+			}
+		}
+	}
+
+	card.InStock, card.StockReason = offerStock(offer)
+	return card
+}
+
+// offerStock maps schema.org ItemAvailability to in/out of stock
+func offerStock(offer map[string]any) (bool, string) {
+	availability := schemaTerm(schemaString(offer["availability"]))
+	switch availability {
+	case "":
+		return true, "no availability given"
+	case "InStock", "InStoreOnly", "OnlineOnly", "LimitedAvailability":
+		return true, "availability " + availability
+	}
+	return false, "availability " + availability
+}
+
+// collectProducts walks decoded JSON-LD (including @graph, ItemList and
+// ProductGroup.hasVariant nesting) and returns every Product object
+func collectProducts(v any) []map[string]any {
+	var out []map[string]any
+	switch x := v.(type) {
+	case []any:
+		for _, item := range x {
+			out = append(out, collectProducts(item)...)
+		}
+	case map[string]any:
+		if hasType(x, "Product") {
+			return []map[string]any{x}
+		}
+		// Sorted so products nested under different keys keep a stable order
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = append(out, collectProducts(x[k])...)
+		}
+	}
+	return out
+}
+
+func hasType(m map[string]any, want string) bool {
+	switch t := m["@type"].(type) {
+	case string:
+		return schemaTerm(t) == want
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && schemaTerm(s) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstOffer returns the Offer (or AggregateOffer) of a product; for a list
+// of offers the first one with a price wins
+func firstOffer(v any) map[string]any {
+	offers := schemaList(v)
+	for _, o := range offers {
+		if _, ok := o["price"]; ok {
+			return o
+		}
+		if _, ok := o["lowPrice"]; ok {
+			return o
+		}
+	}
+	if len(offers) > 0 {
+		return offers[0]
+	}
+	return map[string]any{}
+}
+
+// schemaList returns v as a list of objects, whether it is one object or many
+func schemaList(v any) []map[string]any {
+	switch x := v.(type) {
+	case map[string]any:
+		return []map[string]any{x}
+	case []any:
+		var out []map[string]any
+		for _, item := range x {
+			if m, ok := item.(map[string]any); ok {
+				out = append(out, m)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// schemaString returns a JSON-LD value as text. Numbers are formatted and
+// lists yield their first element.
+func schemaString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return fmt.Sprintf("%.2f", x)
+	case []any:
+		if len(x) > 0 {
+			return schemaString(x[0])
+		}
+	case map[string]any:
+		// {"@id": "..."} references, e.g. a url given as a node
+		return schemaString(x["@id"])
+	}
+	return ""
+}
+
+// schemaTerm strips the vocabulary from a term: "https://schema.org/InStock" -> "InStock"
+func schemaTerm(s string) string {
+	if i := strings.LastIndexAny(s, "/#:"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+func formatCurrency(price float64, currency string) string {
+	switch strings.ToUpper(currency) {
+	case "", "CAD", "USD":
+		return fmt.Sprintf("$%.2f", price)
+	}
+	return fmt.Sprintf("%.2f %s", price, strings.ToUpper(currency))
+}
+
+func isJSONLDScript(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "script" &&
+		strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json")
+}
+
+func isMicrodataProduct(n *html.Node) bool {
+	if n.Type != html.ElementNode || !hasAttr(n, "itemscope") {
+		return false
+	}
+	for _, t := range strings.Fields(attr(n, "itemtype")) {
+		if schemaTerm(t) == "Product" {
+			return true
+		}
+	}
+	return false
+}
+
+// microdataItem converts an itemscope element to the same shape as a
+// JSON-LD object, so both are read by productCard. Only the first value of
+// each property is kept.
+func microdataItem(item *html.Node) map[string]any {
+	props := map[string]any{}
+	if t := strings.Fields(attr(item, "itemtype")); len(t) > 0 {
+		props["@type"] = schemaTerm(t[0])
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			nested := hasAttr(child, "itemscope")
+			if names := strings.Fields(attr(child, "itemprop")); len(names) > 0 {
+				var value any
+				if nested {
+					value = microdataItem(child)
+				} else {
+					value = microdataValue(child)
+				}
+				for _, name := range names {
+					if _, ok := props[name]; !ok {
+						props[name] = value
+					}
+				}
+			}
+			// Properties inside a nested item belong to that item
+			if !nested {
+				walk(child)
+			}
+		}
+	}
+	walk(item)
+	return props
+}
+
+// microdataValue reads a property value as defined by the microdata spec
+func microdataValue(n *html.Node) string {
+	if hasAttr(n, "content") {
+		return attr(n, "content")
+	}
+	switch n.Data {
+	case "a", "link", "area":
+		return attr(n, "href")
+	case "img", "source", "audio", "video", "embed", "iframe", "track":
+		return attr(n, "src")
+	case "data", "meter":
+		return attr(n, "value")
+	case "time":
+		if hasAttr(n, "datetime") {
+			return attr(n, "datetime")
+		}
+	}
+	return nodeText(n)
+}
+
+func hasAttr(n *html.Node, name string) bool {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findAll returns every node under n for which match is true, in document order
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var out []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if match(n) {
+			out = append(out, n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return out
+}
+
+// nodeRawText returns the unprocessed text content of an element, such as a script body
+func nodeRawText(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			b.WriteString(child.Data)
+		}
+	}
+	return b.String()
+}
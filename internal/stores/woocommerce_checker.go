@@ -0,0 +1,115 @@
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+)
+
+// WooCommerceChecker implements checking for stores running the
+// WooCommerce Store API (/wp-json/wc/store/v1)
+type WooCommerceChecker struct {
+	cfg *config.StoreConfig
+}
+
+// wooProduct is the subset of a Store API product we use
+type wooProduct struct {
+	Name          string    `json:"name"`
+	Permalink     string    `json:"permalink"`
+	IsInStock     bool      `json:"is_in_stock"`
+	IsPurchasable bool      `json:"is_purchasable"`
+	Prices        wooPrices `json:"prices"`
+}
+
+// wooPrices holds amounts as strings in the currency's minor unit,
+// e.g. "4999" with currency_minor_unit 2 is 49.99
+type wooPrices struct {
+	Price             string `json:"price"`
+	RegularPrice      string `json:"regular_price"`
+	CurrencyMinorUnit int    `json:"currency_minor_unit"`
+	CurrencyPrefix    string `json:"currency_prefix"`
+	CurrencySuffix    string `json:"currency_suffix"`
+}
+
+// NewWooCommerceChecker creates a new WooCommerce checker from config
+func NewWooCommerceChecker(cfg *config.StoreConfig) *WooCommerceChecker {
+	return &WooCommerceChecker{cfg: cfg}
+}
+
+func (c *WooCommerceChecker) Check(ctx context.Context, q Query) models.StoreResult {
+	rules, err := newMatcher(c.cfg, q)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+	searchURL := fmt.Sprintf("%s/wp-json/wc/store/v1/products?search=%s&per_page=20",
+		c.cfg.BaseURL, url.QueryEscape(q.Text))
+
+	req, err := newRequest(ctx, c.cfg, "GET", searchURL, nil)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	resp, err := doRequest(c.cfg, req)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errorResult(c.cfg.Name, err)
+	}
+
+	var products []wooProduct
+	if err := json.Unmarshal(body, &products); err != nil {
+		return errorResult(c.cfg.Name, &ParseError{Err: err})
+	}
+
+	var matches []models.ProductMatch
+	for _, p := range products {
+		// Product names come back HTML-encoded ("Cascadia &#8211; Landmarks")
+		title := html.UnescapeString(p.Name)
+		if !rules.Matches(title) {
+			continue
+		}
+
+		price, priceNum := p.Prices.format()
+		m := models.ProductMatch{
+			Title:    title,
+			URL:      p.Permalink,
+			Price:    price,
+			PriceNum: priceNum,
+			InStock:  p.IsInStock && p.IsPurchasable,
+		}
+		m.SetRegularPrice(p.Prices.amount(p.Prices.RegularPrice))
+		matches = append(matches, m)
+	}
+
+	return buildResult(c.cfg, matches, q.Text)
+}
+
+// format converts the minor-unit price to a display string and number
+func (p wooPrices) format() (string, float64) {
+	if _, err := strconv.ParseInt(p.Price, 10, 64); err != nil {
+		return "", 0
+	}
+	amount := p.amount(p.Price)
+	return p.CurrencyPrefix + strconv.FormatFloat(amount, 'f', p.CurrencyMinorUnit, 64) + p.CurrencySuffix, amount
+}
+
+// amount converts a minor-unit amount such as "4999" to 49.99
+func (p wooPrices) amount(minor string) float64 {
+	n, err := strconv.ParseInt(minor, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return float64(n) / math.Pow10(p.CurrencyMinorUnit)
+}
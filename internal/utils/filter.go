@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// titlePattern is a plain keyword, matched as a substring ignoring case and
+// accents, or a regular expression when written as /.../
+type titlePattern struct {
+	text string
+	re   *regexp.Regexp
+}
+
+func compileTitlePattern(p string) (titlePattern, error) {
+	if len(p) >= 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+		expr := p[1 : len(p)-1]
+		if _, err := regexp.Compile(expr); err != nil {
+			return titlePattern{}, fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+		return titlePattern{re: regexp.MustCompile("(?i)" + expr)}, nil
+	}
+	return titlePattern{text: foldKeyword(p)}, nil
+}
+
+func (p titlePattern) match(title, folded string) bool {
+	if p.re != nil {
+		return p.re.MatchString(title)
+	}
+	return strings.Contains(folded, p.text)
+}
+
+func foldKeyword(s string) string {
+	return strings.ToLower(foldAccents(s))
+}
+
+// ValidateTitlePattern reports whether p is usable as an include or exclude
+// pattern (see TitleFilter)
+func ValidateTitlePattern(p string) error {
+	if strings.TrimSpace(p) == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	_, err := compileTitlePattern(p)
+	return err
+}
+
+// TitleFilter rules product titles out of a search. A title is excluded
+// when it contains any exclude pattern or lacks one of the include patterns.
+// Patterns are keywords compared ignoring case and accents ("precommande"
+// matches "Précommande"), or case-insensitive regular expressions when
+// written between slashes, e.g. "/\bsleeves?\b/".
+type TitleFilter struct {
+	include []titlePattern
+	exclude []titlePattern
+}
+
+// NewTitleFilter compiles include and exclude patterns. Empty patterns are
+// ignored.
+func NewTitleFilter(include, exclude []string) (*TitleFilter, error) {
+	f := &TitleFilter{}
+	var err error
+	if f.include, err = compileTitlePatterns(include); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = compileTitlePatterns(exclude); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func compileTitlePatterns(list []string) ([]titlePattern, error) {
+	var patterns []titlePattern
+	for _, p := range list {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		tp, err := compileTitlePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, tp)
+	}
+	return patterns, nil
+}
+
+// Excluded reports whether title is ruled out by the filter
+func (f *TitleFilter) Excluded(title string) bool {
+	folded := foldKeyword(title)
+	for _, p := range f.exclude {
+		if p.match(title, folded) {
+			return true
+		}
+	}
+	for _, p := range f.include {
+		if !p.match(title, folded) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package utils
+
+import "testing"
+
+func TestTitleFilter(t *testing.T) {
+	tests := []struct {
+		include, exclude []string
+		title            string
+		want             bool // excluded
+	}{
+		{nil, []string{"précommande"}, "Cascadia - Précommande", true},
+		{nil, []string{"precommande"}, "Cascadia (PRÉCOMMANDE)", true},
+		{nil, []string{"pre-order"}, "Cascadia", false},
+		{nil, []string{"/\\bsleeves?\\b/"}, "Cascadia Sleeves", true},
+		{nil, []string{"/\\bsleeves?\\b/"}, "Sleevesafe Cascadia", false},
+		{[]string{"deluxe"}, nil, "Cascadia Deluxe Edition", false},
+		{[]string{"deluxe"}, nil, "Cascadia", true},
+		{[]string{"deluxe", "/\\(fr\\)/"}, nil, "Cascadia Deluxe (EN)", true},
+		{[]string{""}, []string{" "}, "Cascadia", false}, // empty patterns are ignored
+	}
+	for _, tt := range tests {
+		f, err := NewTitleFilter(tt.include, tt.exclude)
+		if err != nil {
+			t.Fatalf("NewTitleFilter(%q, %q): %v", tt.include, tt.exclude, err)
+		}
+		if got := f.Excluded(tt.title); got != tt.want {
+			t.Errorf("include %q exclude %q: Excluded(%q) = %t, want %t", tt.include, tt.exclude, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTitlePattern(t *testing.T) {
+	for _, p := range []string{"sleeve", "/^promo/", "/"} {
+		if err := ValidateTitlePattern(p); err != nil {
+			t.Errorf("ValidateTitlePattern(%q) = %v, want nil", p, err)
+		}
+	}
+	for _, p := range []string{"", "/(unclosed/"} {
+		if err := ValidateTitlePattern(p); err == nil {
+			t.Errorf("ValidateTitlePattern(%q) = nil, want an error", p)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ligatures are letters NFKD leaves alone, spelled out the way stores write
+// them when the ligature is missing ("Oeuvre", "Ex Aequo")
+var ligatures = strings.NewReplacer(
+	"œ", "oe", "Œ", "oe",
+	"æ", "ae", "Æ", "ae",
+	"ß", "ss",
+)
+
+// conjunctions are written as "&" by some stores and spelled out by others
+var conjunctions = map[string]string{
+	"&":   "and",
+	"and": "and",
+	"et":  "and",
+}
+
+// romanNumerals maps numerals to digits so "Dominion II" matches
+// "Dominion 2". They are only read as numbers where a sequel number goes,
+// at the end of the title or of a part of it, so "Catan x Star Trek" keeps
+// its "x". "i" is left alone: it is more often a word than a numeral.
+var romanNumerals = map[string]string{
+	"ii": "2", "iii": "3", "iv": "4", "v": "5", "vi": "6", "vii": "7",
+	"viii": "8", "ix": "9", "x": "10", "xi": "11", "xii": "12",
+}
+
+// isPartSeparator reports whether r ends a part of a title, as in
+// "Dominion II: Intrigue" or "Mage Knight II (FR)"
+func isPartSeparator(r rune) bool {
+	return strings.ContainsRune(":-–—([{/|,", r)
+}
+
+// stopWords are English and French articles and prepositions that stores
+// add or drop freely ("Les Aventuriers du Rail", "The Crew")
+var stopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "of": true,
+	"le": true, "la": true, "les": true, "l": true, "un": true, "une": true,
+	"de": true, "du": true, "des": true, "d": true,
+}
+
+// foldAccents decomposes s (NFKD) and drops the combining marks, so "É" and
+// "ﬁ" become "E" and "fi"
+func foldAccents(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(ligatures.Replace(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NormalizeWords turns a title into comparable words: accents folded,
+// lowercased, split on anything but letters, digits and "&", with
+// conjunctions and roman numerals canonicalized and stop words removed.
+// Apostrophes and quotes separate words, so "L'Île" gives "ile".
+// A title left without a word but stop words and conjunctions keeps them.
+func NormalizeWords(s string) []string {
+	var words, all []string
+	meaningful := false
+	for _, part := range strings.FieldsFunc(strings.ToLower(foldAccents(s)), isPartSeparator) {
+		var tokens []string
+		for _, f := range strings.FieldsFunc(part, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+		}) {
+			// "Salt&Pepper" splits around the ampersand
+			tokens = append(tokens, splitAmpersand(f)...)
+		}
+
+		for i, w := range tokens {
+			if c, ok := conjunctions[w]; ok {
+				w = c
+			} else if n, ok := romanNumerals[w]; ok && i == len(tokens)-1 {
+				w = n
+			}
+			all = append(all, w)
+			if !stopWords[w] {
+				words = append(words, w)
+				meaningful = meaningful || w != "and"
+			}
+		}
+	}
+	if !meaningful {
+		return all
+	}
+	return words
+}
+
+// splitAmpersand splits "salt&pepper" into "salt", "&", "pepper". Initials
+// such as "d&d" or "r&d" stay one word: read as "d and d", "d" being a
+// stop word, they would match any title with "and" in it.
+func splitAmpersand(f string) []string {
+	if !strings.Contains(f, "&") || f == "&" {
+		return []string{f}
+	}
+	parts := strings.Split(f, "&")
+	initials := true
+	for _, part := range parts {
+		initials = initials && utf8.RuneCountInString(part) == 1
+	}
+	if initials {
+		return []string{f}
+	}
+
+	var out []string
+	for i, part := range parts {
+		if i > 0 {
+			out = append(out, "&")
+		}
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// NormalizeTitle returns the normalized words of a title joined by spaces
+func NormalizeTitle(s string) string {
+	return strings.Join(NormalizeWords(s), " ")
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Élevage", []string{"elevage"}},
+		{"Les Aventuriers du Rail", []string{"aventuriers", "rail"}},
+		{"7 Wonders:", []string{"7", "wonders"}},
+		{"Dune: Imperium", []string{"dune", "imperium"}},
+		{"“Dune” Imperium", []string{"dune", "imperium"}},
+		{"L’Île Interdite", []string{"ile", "interdite"}},
+		{"Tzolk'in", []string{"tzolk", "in"}},
+		{"Dungeons & Dragons", []string{"dungeons", "and", "dragons"}},
+		{"Salt&Pepper", []string{"salt", "and", "pepper"}},
+		{"Petits meurtres et faits divers", []string{"petits", "meurtres", "and", "faits", "divers"}},
+		{"Dominion II", []string{"dominion", "2"}},
+		{"Dominion II: Intrigue", []string{"dominion", "2", "intrigue"}},
+		{"Catan x Star Trek", []string{"catan", "x", "star", "trek"}},
+		{"Rome V Carthage", []string{"rome", "v", "carthage"}},
+		{"D&D", []string{"d&d"}},
+		{"D & D", []string{"d", "and", "d"}}, // only stop words and a conjunction: kept
+		{"Carcassonne: Chasseurs et Cueilleurs", []string{"carcassonne", "chasseurs", "and", "cueilleurs"}},
+		{"Cœur de dragon", []string{"coeur", "dragon"}},
+		{"Le Valet d'Coeur", []string{"valet", "coeur"}},
+		{"Ticket to Ride: Europe", []string{"ticket", "to", "ride", "europe"}},
+		{"The", []string{"the"}}, // only stop words: kept
+	}
+	for _, tt := range tests {
+		if got := NormalizeWords(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NormalizeWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		search, title string
+		want          bool
+	}{
+		{"Elevage", "Élevage (VF)", true},
+		{"Élevage", "Elevage", true},
+		{"Aventuriers du rail", "Les Aventuriers du Rail: Europe", true},
+		{"7 wonders", "7 Wonders: Duel", true},
+		{"Dune Imperium", "Dune: Imperium – Uprising", true},
+		{"Dune: Imperium", "« Dune » Imperium [Français]", true},
+		{"Harmonies & Co", "Harmonies and Co", true},
+		{"Time Stories", "T.I.M.E Stories", false},
+		{"Dominion 2", "Dominion II (2nd Edition)", true},
+		{"Catan x Star Trek", "Catan: Star Trek Edition", false},
+		{"Catan x Star Trek", "Catan 10 Star Trek", false},
+		{"D&D", "Dungeons & Dragons", false},
+		{"D&D", "Petits meurtres et faits divers", false},
+		{"D&D", "D&D Adventure Begins", true},
+		{"Cascadia", "Cascadia Rolling Hills", true},
+		{"Cascade", "Cascadia", false},
+		{"Ark Nova", "Arche Nova", false},
+		{"Azul", "Azul: Summer Pavilion", true},
+		{"Azul", "Azulejos", false},
+		{"", "Azul", false},
+	}
+	for _, tt := range tests {
+		if got := FuzzyMatch(tt.search, tt.title); got != tt.want {
+			t.Errorf("FuzzyMatch(%q, %q) = %t, want %t", tt.search, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestExactTitleMatch(t *testing.T) {
+	tests := []struct {
+		search, title string
+		want          bool
+	}{
+		{"Cascadia", "CASCADIA", true},
+		{"Les Aventuriers du Rail", "Aventuriers du rail", true},
+		{"Dune Imperium", "Dune: Imperium", true},
+		{"Heat: Pedal to the Metal", "Heat – Pedal to the Metal", true},
+		{"Élevage", "Elevage", true},
+		{"Cascadia", "Cascadia Rolling Hills", false},
+	}
+	for _, tt := range tests {
+		if got := ExactTitleMatch(tt.search, tt.title); got != tt.want {
+			t.Errorf("ExactTitleMatch(%q, %q) = %t, want %t", tt.search, tt.title, got, tt.want)
+		}
+	}
+}
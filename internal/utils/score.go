@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+// qualifierWords describe the printing, language or kind of product rather
+// than a different game ("Cascadia (Français)", "Cascadia Board Game"), so
+// they cost less than other extra title words and don't count in the edit
+// distance: editions of the same game keep the store's order.
+var qualifierWords = map[string]bool{
+	"edition": true, "ed": true, "deluxe": true, "collector": true, "collectors": true,
+	"anniversary": true, "revised": true, "version": true, "nouvelle": true, "new": true,
+	"2nd": true, "3rd": true, "4th": true, "5th": true, "6th": true, "2e": true, "3e": true,
+	"fr": true, "vf": true, "francais": true, "francaise": true, "french": true,
+	"en": true, "english": true, "anglais": true, "anglaise": true, "multilingual": true, "multilingue": true,
+	"board": true, "game": true, "jeu": true, "societe": true,
+}
+
+const (
+	extraWordCost = 0.25 // each title word that is not in the search
+	qualifierCost = 0.05 // each extra word from qualifierWords
+	tokenWeight   = 0.75 // the rest of the score is character similarity
+)
+
+// ScoreMatch rates how well a product title answers a search, from 0 (no
+// search word found) to 1 (same normalized title). Missing search words
+// scale the score down, extra title words cost extraWordCost each
+// (qualifier words much less), and the edit distance between the search
+// and the title breaks ties between similar candidates.
+func ScoreMatch(search, title string) float64 {
+	searchWords := NormalizeWords(search)
+	titleWords := NormalizeWords(title)
+	if len(searchWords) == 0 || len(titleWords) == 0 {
+		return 0
+	}
+
+	s := strings.Join(searchWords, " ")
+	t := strings.Join(titleWords, " ")
+	if s == t {
+		return 1
+	}
+
+	wanted := make(map[string]bool, len(searchWords))
+	for _, w := range searchWords {
+		wanted[w] = true
+	}
+	inTitle := make(map[string]bool, len(titleWords))
+	var extra, qualifiers int
+	var core []string // title without qualifier words, for the edit distance
+	for _, w := range titleWords {
+		inTitle[w] = true
+		switch {
+		case wanted[w]:
+		case qualifierWords[w]:
+			qualifiers++
+			continue
+		default:
+			extra++
+		}
+		core = append(core, w)
+	}
+
+	found := 0
+	for w := range wanted {
+		if inTitle[w] {
+			found++
+		}
+	}
+	if found == 0 {
+		return 0
+	}
+	overlap := float64(found) / float64(len(wanted))
+
+	tokens := overlap / (1 + extraWordCost*float64(extra) + qualifierCost*float64(qualifiers))
+	t = strings.Join(core, " ")
+	chars := 1 - float64(levenshtein(s, t))/float64(max(len([]rune(s)), len([]rune(t)), 1))
+
+	score := tokenWeight*tokens + (1-tokenWeight)*chars
+	return math.Round(score*100) / 100
+}
+
+// levenshtein returns the edit distance between a and b in runes
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
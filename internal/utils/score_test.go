@@ -0,0 +1,55 @@
+package utils
+
+import "testing"
+
+func TestScoreMatchRanking(t *testing.T) {
+	// Each title should score strictly lower than the one before it
+	tests := []struct {
+		search string
+		titles []string
+	}{
+		{"Catan", []string{
+			"Catan",
+			"Catan (5th Edition)",
+			"Catan Junior",
+			"Catan: Seafarers",
+			"Catan: Cities & Knights 5-6 Player",
+		}},
+		{"Les Aventuriers du Rail", []string{
+			"Aventuriers du Rail",
+			"Les Aventuriers du Rail - Édition Française",
+			"Les Aventuriers du Rail: Europe",
+			"Les Aventuriers du Rail: Europe 15e Anniversaire",
+		}},
+		{"Dune Imperium", []string{
+			"Dune: Imperium",
+			"Dune: Imperium Uprising",
+			"Dune",
+		}},
+	}
+	for _, tt := range tests {
+		prev := 2.0
+		for _, title := range tt.titles {
+			got := ScoreMatch(tt.search, title)
+			if got >= prev {
+				t.Errorf("ScoreMatch(%q, %q) = %.2f, want below %.2f", tt.search, title, got, prev)
+			}
+			prev = got
+		}
+	}
+}
+
+func TestScoreMatchEditions(t *testing.T) {
+	// Language editions of the same game tie so the store's order is kept
+	fr := ScoreMatch("Cascadia", "Cascadia (Français)")
+	en := ScoreMatch("Cascadia", "Cascadia [Anglais]")
+	if fr != en {
+		t.Errorf("French %.2f and English %.2f editions should tie", fr, en)
+	}
+	if fr < 0.9 {
+		t.Errorf("edition score %.2f, want at least 0.9", fr)
+	}
+	if got := ScoreMatch("Cascadia", "Azul"); got != 0 {
+		t.Errorf("unrelated title scored %.2f, want 0", got)
+	}
+}
@@ -6,60 +6,26 @@ import (
 	"strings"
 )
 
-var wordSplitter = regexp.MustCompile(`[\s\-:,()\[\]]+`)
-
-func splitIntoWords(s string) []string {
-	parts := wordSplitter.Split(s, -1)
-	words := make([]string, 0, len(parts))
-	for _, p := range parts {
-		if p != "" {
-			words = append(words, p)
-		}
-	}
-	return words
-}
-
-// ExactTitleMatch checks if title matches search exactly (ignoring case)
+// ExactTitleMatch checks if title matches search exactly once both are
+// normalized (case, accents, punctuation, stop words)
 func ExactTitleMatch(search, title string) bool {
-	return strings.EqualFold(strings.TrimSpace(search), strings.TrimSpace(title))
-}
-
-// ShouldExclude checks if a product should be filtered out
-func ShouldExclude(title string) bool {
-	t := strings.ToLower(title)
-	return strings.Contains(t, "pre-order") ||
-		strings.Contains(t, "preorder") ||
-		strings.Contains(t, "extension") ||
-		strings.Contains(t, "expansion")
+	return NormalizeTitle(search) == NormalizeTitle(title)
 }
 
-// FuzzyMatch checks if search term matches title using word boundary matching
+// FuzzyMatch checks that every word of the search appears as a complete
+// word of the title, comparing normalized words (see NormalizeWords)
 func FuzzyMatch(search, title string) bool {
-	searchLower := strings.ToLower(search)
-	titleLower := strings.ToLower(title)
-	titleWords := splitIntoWords(titleLower)
-
-	searchWords := strings.Fields(searchLower)
-	if len(searchWords) == 1 {
-		// Single word: must match as complete word
-		for _, w := range titleWords {
-			if w == searchLower {
-				return true
-			}
-		}
+	searchWords := NormalizeWords(search)
+	if len(searchWords) == 0 {
 		return false
 	}
 
-	// Multi-word: all search words must be present as complete words
+	titleWords := make(map[string]bool)
+	for _, w := range NormalizeWords(title) {
+		titleWords[w] = true
+	}
 	for _, sw := range searchWords {
-		found := false
-		for _, tw := range titleWords {
-			if tw == sw {
-				found = true
-				break
-			}
-		}
-		if !found {
+		if !titleWords[sw] {
 			return false
 		}
 	}
@@ -78,3 +44,20 @@ func ParsePrice(priceStr string) float64 {
 	fmt.Sscanf(cleaned, "%f", &price)
 	return price
 }
+
+var (
+	frenchMarker  = regexp.MustCompile(`(?i)(\[(fr|français|francais)\]|\((fr|français|francais|vf)\)|\bversion fran[cç]aise\b|\b[ée]dition fran[cç]aise\b|\bfrench\b)`)
+	englishMarker = regexp.MustCompile(`(?i)(\[(en|anglais|english)\]|\((en|anglais|english)\)|\bversion anglaise\b|\benglish edition\b|\benglish\b)`)
+)
+
+// DetectLanguage guesses a product's edition language from markers in its
+// title such as "[Français]" or "(English)". Returns "fr", "en" or "".
+func DetectLanguage(title string) string {
+	switch {
+	case frenchMarker.MatchString(title):
+		return "fr"
+	case englishMarker.MatchString(title):
+		return "en"
+	}
+	return ""
+}
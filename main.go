@@ -1,128 +1,65 @@
 package main
 
 import (
-	"embed"
-	"encoding/json"
 	"fmt"
-	"io/fs"
-	"log"
-	"net/http"
 	"os"
-	"os/exec"
-	"runtime"
+	"strings"
 
-	"cardboard-hunter/internal/checker"
-	"cardboard-hunter/internal/models"
-	"cardboard-hunter/internal/storage"
+	"cardboard-hunter/internal/stores"
 )
 
-//go:embed static/*
-var staticFiles embed.FS
+const usage = `Usage: cardboard-hunter [command] [flags]
 
-var store *storage.Storage
+Commands:
+  serve           Start the web UI (default when no command is given)
+  check           Check games from the command line and print the results
+  validate-store  Check store config files for errors
+  test-store      Run a store config against a query and show what it parsed
+  import-bgg      Add the wanted games of a BoardGameGeek collection export to games.json
 
-func main() {
-	// Initialize storage
-	store = storage.New("games.json")
-	// Serve static files (need to strip "static/" prefix from embedded FS)
-	staticFS, err := fs.Sub(staticFiles, "static")
-	if err != nil {
-		log.Fatal(err)
-	}
-	http.Handle("/", http.FileServer(http.FS(staticFS)))
-
-	// API endpoints
-	http.HandleFunc("/api/check", handleCheck)
-	http.HandleFunc("/api/games", handleGames)
-	http.HandleFunc("/api/shutdown", handleShutdown)
-
-	port := "8080"
-	url := fmt.Sprintf("http://localhost:%s", port)
-	fmt.Printf("🎲 Cardboard Hunter running at %s\n", url)
-	go openBrowser(url)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
-}
-
-func openBrowser(url string) {
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "windows":
-		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
-	case "darwin":
-		cmd = exec.Command("open", url)
-	default:
-		cmd = exec.Command("xdg-open", url)
-	}
-	cmd.Run()
-}
-
-func handleShutdown(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "shutting down"})
-	go func() {
-		os.Exit(0)
-	}()
-}
-
-func handleCheck(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+Run "cardboard-hunter <command> -h" for the flags of a command.
+`
 
-	var req models.CheckRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	// Create checker and process games
-	c := checker.New()
-	results := c.CheckGames(req.Games)
-	summary := c.CalculateSummary(results)
-
-	response := models.CheckResponse{
-		Results: results,
-		Summary: summary,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
 
-func handleGames(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	switch r.Method {
-	case http.MethodGet:
-		// Load saved games
-		games, err := store.LoadGames()
+func run(args []string) int {
+	// CARDBOARD_FIXTURES=record:<dir> saves every store response,
+	// replay:<dir> answers from saved responses without network access
+	if spec := os.Getenv("CARDBOARD_FIXTURES"); spec != "" {
+		mode, dir, err := stores.ParseFixtureSpec(spec)
 		if err != nil {
-			http.Error(w, "Failed to load games", http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(games)
-
-	case http.MethodPost:
-		// Save games
-		var games []models.Game
-		if err := json.NewDecoder(r.Body).Decode(&games); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-
-		if err := store.SaveGames(games); err != nil {
-			http.Error(w, "Failed to save games", http.StatusInternalServerError)
-			return
+			fmt.Fprintln(os.Stderr, err)
+			return 2
 		}
+		stores.UseFixtures(dir, mode)
+	}
 
-		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	if len(args) == 0 {
+		return runServe(nil)
+	}
 
+	switch args[0] {
+	case "serve":
+		return runServe(args[1:])
+	case "check":
+		return runCheck(args[1:])
+	case "validate-store":
+		return runValidateStore(args[1:])
+	case "test-store":
+		return runTestStore(args[1:])
+	case "import-bgg":
+		return runImportBGG(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return 0
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		// Flags without a command (e.g. --port 9000) go to serve
+		if strings.HasPrefix(args[0], "-") {
+			return runServe(args)
+		}
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", args[0], usage)
+		return 2
 	}
 }
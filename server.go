@@ -0,0 +1,658 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cardboard-hunter/internal/bgg"
+	"cardboard-hunter/internal/cache"
+	"cardboard-hunter/internal/checker"
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/models"
+	"cardboard-hunter/internal/notify"
+	"cardboard-hunter/internal/scheduler"
+	"cardboard-hunter/internal/storage"
+)
+
+//go:embed static/*
+var staticFiles embed.FS
+
+var store *storage.Storage
+
+var history *storage.History
+
+var latest *storage.Results
+
+// latestMu serializes loading, replacing and diffing the latest results, so
+// two checks finishing together don't both alert against the same snapshot
+var latestMu sync.Mutex
+
+var resultCache *cache.Cache
+
+var schedule *scheduler.Scheduler
+
+var alerts *alerting
+
+var jobs = newCheckJobs()
+
+// runServe starts the web UI server (the default command)
+func runServe(args []string) int {
+	fset := flag.NewFlagSet("serve", flag.ContinueOnError)
+	port := fset.Int("port", 8080, "HTTP port to listen on")
+	noBrowser := fset.Bool("no-browser", false, "don't open the browser on start")
+	dataDir := fset.String("data-dir", ".", "directory for games.json, history and results")
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+
+	openStorage(*dataDir)
+
+	loader := config.NewLoader(os.Getenv("CARDBOARD_CONFIG_DIR"))
+	settings, err := loader.LoadSettings()
+	if err != nil {
+		log.Printf("Failed to load settings, using defaults: %v", err)
+		settings = &config.Settings{}
+	}
+	startAlerts(settings.Notifications)
+	startScheduler(settings.Scheduler)
+
+	// Serve static files (need to strip "static/" prefix from embedded FS)
+	staticFS, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	http.Handle("/", http.FileServer(http.FS(staticFS)))
+
+	// API endpoints
+	http.HandleFunc("/api/check", handleCheck)
+	http.HandleFunc("/api/check/stream", handleCheckStream)
+	http.HandleFunc("/api/check/cancel", handleCheckCancel)
+	http.HandleFunc("/api/games", handleGames)
+	http.HandleFunc("/api/import/bgg", handleImportBGG)
+	http.HandleFunc("/api/history", handleHistory)
+	http.HandleFunc("/api/results/latest", handleLatestResults)
+	http.HandleFunc("/api/schedule", handleSchedule)
+	http.HandleFunc("/api/shutdown", handleShutdown)
+
+	url := fmt.Sprintf("http://localhost:%d", *port)
+	fmt.Printf("🎲 Cardboard Hunter running at %s\n", url)
+	if !*noBrowser {
+		go openBrowser(url)
+	}
+	log.Print(http.ListenAndServe(fmt.Sprintf(":%d", *port), nil))
+	return 1
+}
+
+// openStorage points the persisted files at dataDir
+func openStorage(dataDir string) {
+	store = storage.New(filepath.Join(dataDir, "games.json"))
+	history = storage.NewHistory(filepath.Join(dataDir, "history.jsonl"))
+	latest = storage.NewResults(filepath.Join(dataDir, "results.json"))
+
+	var err error
+	resultCache, err = cache.Open(filepath.Join(dataDir, "cache.json"))
+	if err != nil {
+		log.Printf("Failed to load result cache, starting empty: %v", err)
+	}
+}
+
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	cmd.Run()
+}
+
+func handleShutdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "shutting down"})
+	go func() {
+		os.Exit(0)
+	}()
+}
+
+func handleCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, jobID, finish := jobs.start(r.Context())
+	defer finish()
+	w.Header().Set("X-Check-Job", strconv.FormatUint(jobID, 10))
+
+	// Create checker and process games
+	c := checker.New()
+	c.UseCache(resultCache, req.Refresh)
+	results := c.CheckGames(ctx, req.Games)
+	response := completeCheck(ctx, c, results)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func handleCheckStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sse, ok := newSSEWriter(w)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, jobID, finish := jobs.start(r.Context())
+	defer finish()
+
+	c := checker.New()
+	c.UseCache(resultCache, req.Refresh)
+	storeNames := c.StoreNames()
+
+	gameNames := make([]string, len(req.Games))
+	for i, g := range req.Games {
+		gameNames[i] = g.Name
+	}
+	sse.Send("start", models.StreamStart{Job: jobID, Games: gameNames, Stores: storeNames})
+
+	total := len(req.Games) * len(storeNames)
+	done := 0
+	var mu sync.Mutex
+
+	results := c.CheckGamesStream(ctx, req.Games, func(gameIndex, storeIndex int, result models.StoreResult) {
+		// Sent under the lock so progress events go out in order
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		sse.Send("result", models.StreamResult{
+			GameIndex:  gameIndex,
+			StoreIndex: storeIndex,
+			Result:     result,
+		})
+		sse.Send("progress", models.StreamProgress{Done: done, Total: total})
+	})
+
+	response := completeCheck(ctx, c, results)
+	sse.Send("complete", models.StreamComplete{
+		Summary:   response.Summary,
+		AtTarget:  response.AtTarget,
+		OnSale:    response.OnSale,
+		Cancelled: ctx.Err() != nil,
+	})
+}
+
+func handleCheckCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Without a job ID every running check is aborted
+	var cancelled int
+	if idStr := r.URL.Query().Get("job"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid job ID", http.StatusBadRequest)
+			return
+		}
+		if jobs.cancel(id) {
+			cancelled = 1
+		}
+	} else {
+		cancelled = jobs.cancelAll()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"cancelled": cancelled})
+}
+
+// checkJobs tracks running checks so they can be aborted from another request
+type checkJobs struct {
+	mu      sync.Mutex
+	nextID  uint64
+	cancels map[uint64]context.CancelFunc
+}
+
+func newCheckJobs() *checkJobs {
+	return &checkJobs{cancels: make(map[uint64]context.CancelFunc)}
+}
+
+// start registers a new job derived from parent. The returned finish func
+// must be called when the job finishes.
+func (j *checkJobs) start(parent context.Context) (context.Context, uint64, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	j.mu.Lock()
+	j.nextID++
+	id := j.nextID
+	j.cancels[id] = cancel
+	j.mu.Unlock()
+
+	return ctx, id, func() {
+		j.mu.Lock()
+		delete(j.cancels, id)
+		j.mu.Unlock()
+		cancel()
+	}
+}
+
+func (j *checkJobs) cancel(id uint64) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	cancel, ok := j.cancels[id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (j *checkJobs) cancelAll() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, cancel := range j.cancels {
+		cancel()
+	}
+	return len(j.cancels)
+}
+
+// sseWriter serializes Server-Sent Events onto a response
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	mu      sync.Mutex
+}
+
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseWriter{w: w, flusher: flusher}, true
+}
+
+// Send writes a single named event with a JSON payload
+func (s *sseWriter) Send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// completeCheck builds the response for a finished check, records its prices
+// and, unless the check was cancelled, merges it into the latest results.
+// Persistence failures are logged, not fatal to the check.
+func completeCheck(ctx context.Context, c *checker.Checker, results []models.GameResult) models.CheckResponse {
+	response := models.CheckResponse{
+		Results:   results,
+		Summary:   c.CalculateSummary(results),
+		AtTarget:  c.CalculateTargetSummary(results),
+		OnSale:    c.CalculateSaleSummary(results),
+		CheckedAt: time.Now(),
+	}
+
+	if err := history.Record(results, response.CheckedAt); err != nil {
+		log.Printf("Failed to record price history: %v", err)
+	}
+	if err := resultCache.Save(); err != nil {
+		log.Printf("Failed to save result cache: %v", err)
+	}
+	if ctx.Err() != nil {
+		return response
+	}
+
+	latestMu.Lock()
+	defer latestMu.Unlock()
+
+	prev, err := latest.Load()
+	if err != nil {
+		log.Printf("Failed to load previous results: %v", err)
+	}
+
+	// A check of some games only (one game from the UI) must not drop the
+	// others' results: the next check would have nothing to diff them against
+	snapshot := response
+	if prev != nil {
+		wishlist, _ := store.LoadGames()
+		snapshot.Results = mergeSnapshot(prev.Results, results, wishlist)
+		snapshot.Summary = c.CalculateSummary(snapshot.Results)
+		snapshot.AtTarget = c.CalculateTargetSummary(snapshot.Results)
+		snapshot.OnSale = c.CalculateSaleSummary(snapshot.Results)
+	}
+	if err := latest.Save(snapshot); err != nil {
+		log.Printf("Failed to save results: %v", err)
+	}
+	if alerts != nil && prev != nil {
+		alerts.send(prev.Results, results)
+	}
+	return response
+}
+
+// mergeSnapshot returns the saved results with the games checked in curr
+// replaced or added, matched by name. When the wishlist is known, games no
+// longer on it are dropped and the rest follow its order.
+func mergeSnapshot(prev, curr []models.GameResult, wishlist []models.Game) []models.GameResult {
+	byName := make(map[string]models.GameResult, len(prev)+len(curr))
+	var order []string
+	add := func(gr models.GameResult) {
+		key := strings.ToLower(gr.Name)
+		if _, ok := byName[key]; !ok {
+			order = append(order, key)
+		}
+		byName[key] = gr
+	}
+	for _, gr := range prev {
+		add(gr)
+	}
+	for _, gr := range curr {
+		add(gr)
+	}
+
+	if len(wishlist) > 0 {
+		order = nil
+		listed := make(map[string]bool, len(wishlist))
+		for _, g := range wishlist {
+			key := strings.ToLower(g.Name)
+			if _, ok := byName[key]; ok && !listed[key] {
+				listed[key] = true
+				order = append(order, key)
+			}
+		}
+		// Games just checked without being on the wishlist are kept too
+		for _, gr := range curr {
+			if key := strings.ToLower(gr.Name); !listed[key] {
+				listed[key] = true
+				order = append(order, key)
+			}
+		}
+	}
+
+	merged := make([]models.GameResult, 0, len(order))
+	for _, key := range order {
+		merged = append(merged, byName[key])
+	}
+	return merged
+}
+
+// alerting diffs consecutive checks and notifies about restocks and price targets
+type alerting struct {
+	dispatcher *notify.Dispatcher
+	rules      map[string]config.GameAlertRule
+}
+
+// startAlerts enables notifications when configured in settings.json
+func startAlerts(cfg config.NotificationsConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	dispatcher, err := notify.NewDispatcher(cfg)
+	if err != nil {
+		log.Printf("Notifications disabled: %v", err)
+		return
+	}
+	alerts = &alerting{dispatcher: dispatcher, rules: cfg.Games}
+}
+
+func (a *alerting) send(prev, curr []models.GameResult) {
+	found := notify.Diff(prev, curr, a.gameRules())
+	if len(found) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := a.dispatcher.Dispatch(ctx, found); err != nil {
+			log.Printf("Failed to send notifications: %v", err)
+		}
+	}()
+}
+
+// gameRules merges wishlist target prices into the configured alert rules.
+// A target set in settings.json takes precedence.
+func (a *alerting) gameRules() map[string]config.GameAlertRule {
+	rules := make(map[string]config.GameAlertRule, len(a.rules))
+	for name, r := range a.rules {
+		rules[name] = r
+	}
+
+	games, err := store.LoadGames()
+	if err != nil {
+		return rules
+	}
+	for _, g := range games {
+		if g.TargetPrice == 0 {
+			continue
+		}
+		key := g.Name
+		for name := range rules {
+			if strings.EqualFold(name, g.Name) {
+				key = name
+				break
+			}
+		}
+		r := rules[key]
+		if r.TargetPrice == 0 {
+			r.TargetPrice = g.TargetPrice
+		}
+		rules[key] = r
+	}
+	return rules
+}
+
+// startScheduler starts background wishlist checks when enabled in settings.json
+func startScheduler(cfg config.SchedulerConfig) {
+	if !cfg.Enabled {
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Printf("Scheduled checks disabled: %v", err)
+		return
+	}
+
+	interval := config.ParseDuration(cfg.Interval, config.DefaultSchedulerInterval)
+	jitter := config.ParseDuration(cfg.Jitter, 0)
+	schedule = scheduler.New(interval, jitter, runScheduledCheck)
+
+	var lastRun time.Time
+	if prev, err := latest.Load(); err == nil && prev != nil {
+		lastRun = prev.CheckedAt
+	}
+	schedule.Start(context.Background(), lastRun)
+	log.Printf("Scheduled checks every %s", interval)
+}
+
+func runScheduledCheck(parent context.Context) error {
+	games, err := store.LoadGames()
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return nil
+	}
+
+	// Scheduled checks run under the scheduler's context rather than as
+	// jobs, so cancelling the checks started from the page leaves them be.
+	// They always fetch fresh prices and refill the cache.
+	c := checker.New()
+	c.UseCache(resultCache, true)
+	completeCheck(parent, c, c.CheckGames(parent, games))
+	return parent.Err()
+}
+
+func handleLatestResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := latest.Load()
+	if err != nil {
+		http.Error(w, "Failed to load results", http.StatusInternalServerError)
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func handleSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := models.ScheduleStatus{}
+	if schedule != nil {
+		status = schedule.Status()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	game := r.URL.Query().Get("game")
+	if game == "" {
+		http.Error(w, "Missing game parameter", http.StatusBadRequest)
+		return
+	}
+
+	points, err := history.GameHistory(game)
+	if err != nil {
+		http.Error(w, "Failed to load history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.HistoryResponse{Game: game, Points: points})
+}
+
+func handleGames(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		// Load saved games
+		games, err := store.LoadGames()
+		if err != nil {
+			http.Error(w, "Failed to load games", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(games)
+
+	case http.MethodPost:
+		// Save games
+		var games []models.Game
+		if err := json.NewDecoder(r.Body).Decode(&games); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := store.SaveGames(games); err != nil {
+			http.Error(w, "Failed to save games", http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// maxImportSize bounds an uploaded BGG collection export
+const maxImportSize = 10 << 20
+
+// handleImportBGG merges the wanted games of a BGG collection export (the
+// request body) into the saved wishlist and returns the merged list
+func handleImportBGG(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, err := bgg.ParseCollection(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	games, err := store.LoadGames()
+	if err != nil {
+		http.Error(w, "Failed to load games", http.StatusInternalServerError)
+		return
+	}
+	res, err := bgg.Merge(games, collection)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := store.SaveGames(res.Games); err != nil {
+		http.Error(w, "Failed to save games", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
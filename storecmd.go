@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"cardboard-hunter/internal/config"
+	"cardboard-hunter/internal/stores"
+	"cardboard-hunter/internal/utils"
+)
+
+// runValidateStore schema-checks store config files and compiles their patterns
+func runValidateStore(args []string) int {
+	fset := flag.NewFlagSet("validate-store", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: cardboard-hunter validate-store <store.json> [...]")
+	}
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, path := range fset.Args() {
+		if _, err := loadStoreFile(path); err != nil {
+			fmt.Printf("✗ %s\n", path)
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+			status = 1
+			continue
+		}
+		fmt.Printf("✓ %s\n", path)
+	}
+	return status
+}
+
+// runTestStore runs one store config against a query and prints what it parsed
+func runTestStore(args []string) int {
+	fset := flag.NewFlagSet("test-store", flag.ContinueOnError)
+	htmlFile := fset.String("html", "", "parse this saved search page instead of fetching (HTML store types only)")
+	showHTML := fset.Bool("show-html", false, "print the raw HTML of every card")
+	include := fset.String("include", "", "comma-separated patterns every matching title must contain")
+	exclude := fset.String("exclude", "", "comma-separated patterns ruling titles out")
+	expansion := fset.Bool("expansion", false, "the query is an expansion: don't skip titles saying \"expansion\"")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: cardboard-hunter test-store [flags] <store.json> <query>")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	if fset.NArg() != 2 {
+		fset.Usage()
+		return 2
+	}
+	path, query := fset.Arg(0), fset.Arg(1)
+	q := stores.Query{
+		Text:      query,
+		Include:   splitPatterns(*include),
+		Exclude:   splitPatterns(*exclude),
+		Expansion: *expansion,
+	}
+
+	cfg, err := loadStoreFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s is invalid:\n%v\n", path, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Store: %s (%s)\n", cfg.Name, cfg.Type)
+
+	htmlStore := cfg.Type == config.StoreTypeHTMLScraper || cfg.Type == config.StoreTypeSelector ||
+		cfg.Type == config.StoreTypeStructured
+	if !htmlStore {
+		if *htmlFile != "" {
+			fmt.Fprintln(os.Stderr, "--html is only supported for html_scraper, html_selector and structured_data stores")
+			return 2
+		}
+		var store stores.Store = stores.NewGenericStore(cfg)
+		if cfg.Type == config.StoreTypeBuiltin {
+			// Only settings for a store implemented in Go: run that store
+			if store = stores.NewBuiltinStore(cfg); store == nil {
+				fmt.Fprintf(os.Stderr, "%s: type %q needs the id of a store implemented in Go, such as \"larevanche\"; %q is not one\n",
+					path, cfg.Type, cfg.ID)
+				return 1
+			}
+		}
+		result := store.Check(ctx, q)
+		if result.Error != "" {
+			fmt.Printf("Error: %s\n", result.Error)
+			return 1
+		}
+		fmt.Printf("Matches: %d\n", len(result.Matches))
+		for i, m := range result.Matches {
+			fmt.Printf("\n%d. %s\n   url:   %s\n   price: %s\n   stock: %t\n   confidence: %.2f\n", i+1, m.Title, m.URL, m.Price, m.InStock, m.Confidence)
+			if m.OnSale {
+				fmt.Printf("   sale:  %d%% off %.2f\n", m.DiscountPercent, m.RegularPrice)
+			}
+		}
+		return 0
+	}
+
+	var sc pageTracer
+	var splitOn string
+	switch cfg.Type {
+	case config.StoreTypeSelector:
+		sc, err = stores.NewSelectorChecker(cfg)
+		splitOn = fmt.Sprintf("card selector %q", cfg.Selector.Card)
+	case config.StoreTypeStructured:
+		sc = stores.NewStructuredChecker(cfg)
+		splitOn = "schema.org Products"
+	default:
+		sc, err = stores.NewScraperChecker(cfg)
+		splitOn = fmt.Sprintf("split on %q", cfg.Scraper.CardSplitter)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var html string
+	if *htmlFile != "" {
+		data, err := os.ReadFile(*htmlFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", *htmlFile, err)
+			return 1
+		}
+		html = string(data)
+		fmt.Printf("Source: %s\n", *htmlFile)
+	} else {
+		fmt.Printf("Source: %s\n", sc.SearchURL(query))
+		if html, err = sc.Fetch(ctx, query); err != nil {
+			fmt.Fprintf(os.Stderr, "Fetch failed: %v\n", err)
+			return 1
+		}
+	}
+
+	cards, err := sc.Trace(html, q)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Cards: %d (%s)\n", len(cards), splitOn)
+
+	matched := 0
+	for _, card := range cards {
+		printCard(card, query, *showHTML)
+		if card.Matched {
+			matched++
+		}
+	}
+	fmt.Printf("\n%d of %d cards match %q\n", matched, len(cards), query)
+	return 0
+}
+
+// pageTracer is implemented by the HTML store checkers
+type pageTracer interface {
+	SearchURL(query string) string
+	Fetch(ctx context.Context, query string) (string, error)
+	Trace(html string, q stores.Query) ([]stores.CardTrace, error)
+}
+
+// splitPatterns splits a comma-separated flag value, dropping empty entries
+func splitPatterns(s string) []string {
+	var patterns []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+func printCard(card stores.CardTrace, query string, showHTML bool) {
+	fmt.Printf("\nCard %d\n", card.Index)
+	if showHTML {
+		fmt.Printf("  html:  %s\n", strings.Join(strings.Fields(card.HTML), " "))
+	}
+
+	if card.TitleSource == "" {
+		fmt.Println("  title: no title pattern or selector matched")
+		fmt.Println("  => skipped")
+		return
+	}
+
+	fmt.Printf("  title: %s matched\n", card.TitleSource)
+	if card.TitleMatch != nil {
+		for i, g := range card.TitleMatch[1:] {
+			fmt.Printf("    group %d: %q\n", i+1, g)
+		}
+	}
+	fmt.Printf("    => title %q, url %q\n", card.Title, card.URL)
+
+	if card.PriceSource == "" {
+		fmt.Println("  price: not found")
+	} else {
+		fmt.Printf("  price: %s (%.2f) from %s\n", card.Price, card.PriceNum, card.PriceSource)
+	}
+	if card.RegularSource != "" {
+		fmt.Printf("  regular price: %.2f from %s\n", card.RegularPrice, card.RegularSource)
+	}
+
+	stock := "out of stock"
+	if card.InStock {
+		stock = "in stock"
+	}
+	fmt.Printf("  stock: %s (%s)\n", stock, card.StockReason)
+
+	switch {
+	case card.Excluded:
+		fmt.Println("  => skipped: title excluded")
+	case !card.Matched:
+		fmt.Println("  => skipped: title does not match the query")
+	default:
+		fmt.Printf("  => MATCH (confidence %.2f)\n", utils.ScoreMatch(query, card.Title))
+	}
+}
+
+// loadStoreFile strictly decodes and validates a store config file
+func loadStoreFile(path string) (*config.StoreConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.DecodeStoreConfigStrict(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}